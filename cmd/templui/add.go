@@ -1,55 +1,51 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // runAdd handles the 'add' command logic.
-func runAdd(args []string, commandArg string, force bool, installed bool) {
+func runAdd(args []string, ref string, force bool, installed bool, merge bool, dryRun bool, keepBackups bool) {
 	targetRef := getDefaultRef()
-	commandRefProvided := false
-
-	// Parse optional @ref from the command argument.
-	if strings.Contains(commandArg, "@") {
-		parts := strings.SplitN(commandArg, "@", 2)
-		if len(parts) == 2 && parts[0] == "add" && parts[1] != "" {
-			targetRef = parts[1]
-			commandRefProvided = true
-			fmt.Printf("Using specified ref from command: %s\n", targetRef)
-		} else {
-			fmt.Printf("Error: Invalid format '%s'. Use 'add' or 'add@<ref>'.\n", commandArg)
-			return
-		}
-	} else if commandArg != "add" {
-		fmt.Printf("Error: Unknown command '%s'. Did you mean 'add'?\n", commandArg)
-		showHelp(nil, getDefaultRef())
-		return
+	commandRefProvided := ref != ""
+	if commandRefProvided {
+		targetRef = ref
+		fmt.Printf("Using specified ref from command: %s\n", targetRef)
 	}
 
-	remainingArgs := args[1:]
+	result.Ref = targetRef
+	remainingArgs := args
 
 	// Ensure component arguments are provided after the command.
 	if len(remainingArgs) == 0 && !installed {
-		fmt.Println("Error: No component(s) specified after 'add'.")
-		fmt.Println("Usage: templui add[@<ref>] <component>... | * | templui --installed add[@<ref>]")
+		failf("Error: No component(s) specified after 'add'.\n")
+		fmt.Println("Usage: templui add[@<ref>] <component>... | * | templui add[@<ref>] --installed")
 		return
 	}
 
 	// Disallow combining --installed with explicit component names.
 	if installed && len(remainingArgs) > 0 {
-		fmt.Println("Error: Cannot combine --installed with explicit component names.")
-		fmt.Println("Usage: templui --installed add[@<ref>]")
+		failf("Error: Cannot combine --installed with explicit component names.\n")
+		fmt.Println("Usage: templui add[@<ref>] --installed")
 		return
 	}
 
 	// Load user configuration.
 	config, err := loadConfig()
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+		failf("Error loading config: %v\n", err)
+		return
+	}
+
+	// Load the lockfile to record what gets installed.
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
 		return
 	}
 
@@ -58,9 +54,9 @@ func runAdd(args []string, commandArg string, force bool, installed bool) {
 	isInstallAll := false
 
 	if installed {
-		names, err := getInstalledComponentNames(config.ComponentsDir)
+		names, err := getInstalledComponentNames(config, lock)
 		if err != nil {
-			fmt.Printf("Error detecting installed components: %v\n", err)
+			failf("Error detecting installed components: %v\n", err)
 			return
 		}
 		if len(names) == 0 {
@@ -72,7 +68,7 @@ func runAdd(args []string, commandArg string, force bool, installed bool) {
 		firstCompArg := remainingArgs[0]
 		if firstCompArg == "*" {
 			if len(remainingArgs) > 1 { // Only '*' allowed after 'add[*]' command.
-				fmt.Println("Error: '*' must be the only component argument after 'add'.")
+				failf("Error: '*' must be the only component argument after 'add'.\n")
 				fmt.Println("Usage: templui add[@<ref>] *")
 				return
 			}
@@ -81,14 +77,15 @@ func runAdd(args []string, commandArg string, force bool, installed bool) {
 			// Parse individual component names.
 			for _, arg := range remainingArgs {
 				// Disallow @ref on individual components if ref was given with the command.
-				if strings.Contains(arg, "@") {
-					compName := strings.SplitN(arg, "@", 2)[0]
+				scope, nameAndRef := splitScope(arg)
+				if strings.Contains(nameAndRef, "@") {
+					compName := qualifiedName(scope, strings.SplitN(nameAndRef, "@", 2)[0])
 					if commandRefProvided {
 						fmt.Printf("Warning: Ignoring '@...' for component '%s' because ref '%s' was specified with the 'add' command.\n", compName, targetRef)
 						componentsToInstallNames = append(componentsToInstallNames, compName)
 					} else {
 						// Enforce specifying the ref only with the 'add' command itself.
-						fmt.Printf("Error: Specify the ref with the 'add' command (e.g., 'add@%s %s'), not on individual components like '%s'.\n", targetRef, compName, arg)
+						failf("Error: Specify the ref with the 'add' command (e.g., 'add@%s %s' or 'add --ref %s %s'), not on individual components like '%s'.\n", targetRef, compName, targetRef, compName, arg)
 						return
 					}
 				} else {
@@ -103,15 +100,19 @@ func runAdd(args []string, commandArg string, force bool, installed bool) {
 	fmt.Printf("🔍 Fetching component registry from ref '%s'...\n", targetRef)
 	registry, err := fetchRegistry(targetRef)
 	if err != nil {
-		if strings.Contains(err.Error(), "status code 404") {
-			fmt.Printf("❌ Error fetching registry: %v\n", err)
+		if errors.Is(err, errNotFound) {
+			failf("❌ Error fetching registry: %v\n", err)
 			fmt.Printf("   Check if the ref '%s' exists and contains the file '%s'.\n", targetRef, registryPath)
-			fmt.Printf("   Registry URL attempted: %s%s/%s\n", rawContentBaseURL, targetRef, registryPath)
+			fmt.Printf("   Registry location attempted: %s\n", source.location(targetRef, registryPath))
 		} else {
-			fmt.Printf("❌ Error fetching registry: %v\n", err)
+			failf("❌ Error fetching registry: %v\n", err)
 		}
 		return
 	}
+	if err := checkCLIVersion(registry); err != nil {
+		failf("❌ %v\n", err)
+		return
+	}
 	fmt.Printf("✅ Using components from templui registry (ref: %s)\n", targetRef)
 
 	// Build a map for quick component lookup.
@@ -129,56 +130,89 @@ func runAdd(args []string, commandArg string, force bool, installed bool) {
 		}
 	}
 
-	fmt.Print("\n" + strings.Repeat("─", 50) + "\n")
-	fmt.Printf("🔧 INSTALLING COMPONENTS\n")
-	fmt.Printf("%s\n", strings.Repeat("─", 50))
+	if !dryRun {
+		fmt.Print("\n" + strings.Repeat("─", 50) + "\n")
+		fmt.Printf("🔧 INSTALLING COMPONENTS\n")
+		fmt.Printf("%s\n", strings.Repeat("─", 50))
+	}
+
+	opts := &installOptions{force: force, merge: merge, dryRun: dryRun, keepBackups: keepBackups, lock: lock, hashes: registry.FileHashes()}
 
-	// Track installed state and required utils for this run.
-	installedComponents := make(map[string]bool)
-	requiredUtils := make(map[string]bool)
+	// Components of named registries (e.g., "@acme/datagrid") come from the registries configured in .templui.json.
+	err = addNamedRegistries(componentsToInstallNames, componentMap, opts.hashes)
+	if err != nil {
+		failf("❌ Error fetching registry: %v\n", err)
+		return
+	}
 
-	// Install each requested component and its dependencies.
+	// Resolve the requested components and their dependencies.
+	var requestedNames []string
 	for _, componentName := range componentsToInstallNames {
-		compDef, exists := componentMap[componentName]
-		if !exists {
-			fmt.Printf("❌ Component '%s' not found in registry for ref '%s'.\n", componentName, targetRef)
+		if _, exists := componentMap[componentName]; !exists {
+			if scope, _ := splitScope(componentName); scope != "" {
+				failf("❌ Component '%s' not found in registry '%s'.\n", componentName, scope)
+				continue
+			}
+			failf("❌ Component '%s' not found in registry for ref '%s'.\n", componentName, targetRef)
 			fmt.Println("Available components in this registry:")
 			for _, availableComp := range registry.Components {
 				fmt.Printf("   • %s\n", availableComp.Name)
 			}
 			continue // Skip to next requested component
 		}
+		requestedNames = append(requestedNames, componentName)
+	}
+	componentsToInstall, requiredUtils, err := resolveComponents(requestedNames, componentMap, targetRef)
+	if err != nil {
+		failf("❌ Error resolving dependencies: %v\n", err)
+		fmt.Println("   Run 'templui graph' to inspect the dependencies of the registry.")
+		return
+	}
 
-		// Pass the force flag down to the installation function.
-		err = installComponent(config, compDef, componentMap, targetRef, installedComponents, requiredUtils, force)
-		if err != nil {
-			fmt.Printf("❌ Error installing component %s: %v\n", componentName, err)
-			// Decide whether to continue or stop on error
+	// Requested components are explicit. With --installed, components the lockfile doesn't know yet
+	// (e.g., in a project without a lockfile) are too, since it's unknown why they were installed.
+	var explicitNames []string
+	for _, componentName := range requestedNames {
+		if _, locked := lock.Components[componentName]; !installed || !locked {
+			explicitNames = append(explicitNames, componentName)
 		}
 	}
 
-	// Install all collected required utils.
-	if len(requiredUtils) > 0 {
-		fmt.Printf("\n🛠️  Installing required utils...\n")
-		utilsToInstallPaths := []string{}
-		for utilPath := range requiredUtils {
-			utilsToInstallPaths = append(utilsToInstallPaths, utilPath)
-		}
-		// Pass the force flag down.
-		err = installUtils(config, utilsToInstallPaths, targetRef, force)
-		if err != nil {
-			fmt.Printf("❌ Error installing utils: %v\n", err)
-		}
+	// Install the components and their required utils.
+	err = install(config, targetRef, componentsToInstall, requiredUtils, opts)
+	if err != nil {
+		recordInstall(opts)
+		failEach("❌ Error installing %v\n", err)
+		fmt.Print("\n" + strings.Repeat("─", 50) + "\n")
+		fmt.Printf("❌ INSTALLATION FAILED\n")
+		fmt.Printf("%s\n", strings.Repeat("─", 50))
+		return
+	}
+	for _, componentName := range explicitNames {
+		lock.markExplicit(componentName)
+	}
+
+	recordInstall(opts)
+	if dryRun {
+		printInstallPlan(opts.actions)
+		return
+	}
+
+	err = saveLockfile(lock)
+	if err != nil {
+		failf("❌ Error updating %s: %v\n", lockFileName, err)
 	}
 
 	fmt.Print("\n" + strings.Repeat("─", 50) + "\n")
 	fmt.Printf("✅ INSTALLATION COMPLETED\n")
 	fmt.Printf("%s\n", strings.Repeat("─", 50))
 
+	printMergeSummary(opts)
+
 	// Check if any installed components have JavaScript
 	hasJSComponents := false
-	for compName := range installedComponents {
-		if comp, exists := componentMap[compName]; exists && comp.HasJS {
+	for _, comp := range componentsToInstall {
+		if comp.HasJS {
 			hasJSComponents = true
 			break
 		}
@@ -189,378 +223,294 @@ func runAdd(args []string, commandArg string, force bool, installed bool) {
 	}
 }
 
-// installComponent handles the installation of a single component and its dependencies.
-func installComponent(
-	config Config,
-	comp ComponentDef,
-	componentMap map[string]ComponentDef,
-	ref string,
-	installed map[string]bool,
-	requiredUtils map[string]bool,
-	force bool,
-) error {
-	if installed[comp.Name] {
-		return nil // Already processed in this run
-	}
-	installed[comp.Name] = true
-
-	fmt.Printf("\n📦 Processing component: %s (from ref: %s)\n", comp.Name, ref)
-
-	// Install dependencies first recursively.
-	for _, depName := range comp.Dependencies {
-		if installed[depName] {
-			continue
-		}
-		depComp, exists := componentMap[depName]
-		if !exists {
-			fmt.Printf("Warning: Dependency '%s' for component '%s' not found in registry for ref '%s'. Skipping dependency.\n", depName, comp.Name, ref)
-			continue
-		}
-		// Pass force flag to recursive calls.
-		err := installComponent(config, depComp, componentMap, ref, installed, requiredUtils, force)
-		if err != nil {
-			return fmt.Errorf("failed to install dependency '%s' for '%s': %w", depName, comp.Name, err)
-		}
-		fmt.Printf("   ✅ Installed dependency: %s\n", depName)
-	}
+// installOptions controls how existing files are handled during an installation
+// and collects what happened to them.
+type installOptions struct {
+	force       bool              // Overwrite existing files without asking
+	merge       bool              // Three-way merge local changes into updated component files
+	dryRun      bool              // Only plan the installation without touching disk or prompting
+	keepBackups bool              // Keep replaced files as <file>.orig
+	lock        *Lockfile         // Records installed files (may be nil)
+	hashes      map[string]string // Published file hashes to verify downloads against, keyed by repository path
+
+	actions   []fileAction // What happened (or would happen) to each file
+	merged    []string     // Files into which local changes were merged cleanly
+	conflicts []string     // Files written with conflict markers
+}
 
-	// Download and write component files.
-	fmt.Printf("   📁 Installing files for: %s\n", comp.Name)
-	repoComponentBasePath := "internal/components/"
+// Actions performed on files during an installation.
+const (
+	actionCreate    = "create"     // File doesn't exist yet
+	actionOverwrite = "overwrite"  // Existing file is replaced
+	actionMerge     = "merge"      // Local changes are merged into the new version
+	actionSkip      = "skip"       // Existing file is already at the requested ref
+	actionPrompt    = "prompt"     // Existing file is at another ref; the user is asked (dry-run only)
+	actionKeep      = "keep"       // User declined overwriting the existing file
+	actionAddScript = "add-script" // Script() template is appended to a .templ file
+	actionInstall   = "install"    // CLI is installed from a release binary or with 'go install'
+)
 
-	for _, repoFilePath := range comp.Files {
-		// Determine the destination path, preserving subdirectory structure.
-		var destPath string
-		if strings.HasPrefix(repoFilePath, repoComponentBasePath) {
-			relativePath := repoFilePath[len(repoComponentBasePath):]
-			destPath = filepath.Join(config.ComponentsDir, relativePath)
-		} else {
-			// Fallback for unexpected paths (shouldn't happen with proper manifest).
-			fmt.Printf("  Warning: File path '%s' does not start with '%s'. Placing it directly in '%s'.\n", repoFilePath, repoComponentBasePath, config.ComponentsDir)
-			fileName := filepath.Base(repoFilePath)
-			destPath = filepath.Join(config.ComponentsDir, fileName)
-		}
+// fileAction records what an installation did, or would do in dry-run mode, with a file.
+type fileAction struct {
+	Kind        string `json:"kind"`   // "component", "util", "js", "script", "config", "template" or "cli"
+	Name        string `json:"name"`   // Component name or util path
+	Path        string `json:"path"`   // Destination path
+	Action      string `json:"action"` // One of the action* constants
+	Ref         string `json:"ref,omitempty"`
+	ExistingRef string `json:"existingRef,omitempty"`
+}
 
-		// Ensure the destination directory exists.
-		compDestDir := filepath.Dir(destPath)
-		err := os.MkdirAll(compDestDir, 0755)
-		if err != nil {
-			return fmt.Errorf("failed to create destination directory '%s': %w", compDestDir, err)
-		}
+// record adds a file action to the installation report.
+func (o *installOptions) record(action fileAction) {
+	o.actions = append(o.actions, action)
+}
 
-		// Check if file exists and handle overwrite logic.
-		fileExists := false
-		if _, err := os.Stat(destPath); err == nil {
-			fileExists = true
+// plannedAction returns the action recorded for a destination path, or "" if there is none.
+func (o *installOptions) plannedAction(path string) string {
+	for _, action := range o.actions {
+		if action.Path == path && action.Kind != "script" {
+			return action.Action
 		}
+	}
+	return ""
+}
 
-		shouldWriteFile := true // Assume write unless file exists and is up-to-date or user skips.
+// planFileWrite decides what to do with a destination file based on its existing version.
+func planFileWrite(destPath, ref string, opts *installOptions, canMerge bool) (action string, existingRef string) {
+	if _, err := os.Stat(destPath); err != nil {
+		return actionCreate, ""
+	}
+	existingRef, _ = readFileVersion(destPath)
+	switch {
+	case existingRef == ref && !opts.force:
+		return actionSkip, existingRef
+	case existingRef == ref:
+		return actionOverwrite, existingRef
+	case canMerge && opts.merge && existingRef != "":
+		return actionMerge, existingRef
+	case opts.force:
+		return actionOverwrite, existingRef
+	default:
+		return actionPrompt, existingRef
+	}
+}
 
-		if fileExists {
-			existingRef, _ := readFileVersion(destPath)
-			if existingRef == ref {
-				// File is up-to-date, but check if force flag is set
-				if force {
-					fmt.Printf("      ⚠️  File '%s' already up-to-date (ref: %s). Forcing overwrite.\n", destPath, ref)
-				} else {
-					fmt.Printf("      ℹ️  File '%s' already up-to-date (ref: %s). Skipping.\n", destPath, ref)
-					shouldWriteFile = false
-				}
-			} else {
-				// Versions differ or existing version couldn't be read.
-				if force {
-					fmt.Printf("      ⚠️  File '%s' exists (Version: '%s'). Forcing overwrite with ref '%s'.\n", destPath, existingRef, ref)
-				} else {
-					shouldOverwrite := askForOverwrite(destPath, existingRef, ref)
-					if !shouldOverwrite {
-						fmt.Printf("      ⏭️  Skipping overwrite for '%s'.\n", destPath)
-						shouldWriteFile = false
-					}
-				}
-			}
+// lockComponent records an installed component and the hashes of its files in the lockfile.
+// Files are hashed as they are on disk, unless lockSHAs has a hash for their destination path.
+func lockComponent(lock *Lockfile, config Config, comp ComponentDef, ref string, destPaths []string, lockSHAs map[string]string) error {
+	locked := LockedComponent{
+		Ref:           ref,
+		Commit:        resolveCommit(comp.Name, ref),
+		Dependencies:  comp.Dependencies,
+		RequiredUtils: comp.RequiredUtils,
+	}
+	for i, destPath := range destPaths {
+		lockedFile, err := lockedFileFor(destPath, source.location(ref, comp.Files[i]))
+		if err != nil {
+			return err
 		}
-
-		// Proceed with download and write only if necessary.
-		if shouldWriteFile {
-			fileURL := rawContentBaseURL + ref + "/" + repoFilePath
-			fmt.Printf("      ⬇️  Downloading %s...\n", fileURL)
-			data, err := downloadFile(fileURL)
-			if err != nil {
-				fileNameForError := filepath.Base(repoFilePath)
-				return fmt.Errorf("failed to download file '%s' for component '%s' from %s: %w", fileNameForError, comp.Name, fileURL, err)
-			}
-
-			// Add version comment with documentation link and replace imports.
-			versionComment := fmt.Sprintf("// templui component %s - version: %s installed by templui %s\n", comp.Name, ref, version)
-			versionComment += fmt.Sprintf("// 📚 Documentation: https://templui.io/docs/components/%s\n", comp.Slug)
-			modifiedData := append([]byte(versionComment), data...)
-			if strings.HasSuffix(repoFilePath, ".templ") || strings.HasSuffix(repoFilePath, ".go") {
-				modifiedData = replaceImports(modifiedData, config, comp.Name)
-			}
-
-			// Write the file.
-			err = os.WriteFile(destPath, modifiedData, 0644)
-			if err != nil {
-				return fmt.Errorf("failed to write file '%s': %w", destPath, err)
-			}
-			if fileExists {
-				fmt.Printf("      ✅ Overwritten %s\n", destPath)
-			} else {
-				fmt.Printf("      ✅ Installed %s\n", destPath)
-			}
+		if sha := lockSHAs[destPath]; sha != "" {
+			lockedFile.SHA256 = sha
 		}
+		locked.Files = append(locked.Files, lockedFile)
 	}
-
-	// Collect required utils for later installation.
-	for _, repoUtilPath := range comp.RequiredUtils {
-		requiredUtils[repoUtilPath] = true
-	}
-
-	// Handle JavaScript files if component requires them
 	if comp.HasJS && config.JSDir != "" {
-		err := installComponentJS(config, comp, ref, force)
-		if err != nil {
-			return fmt.Errorf("failed to install JavaScript for component '%s': %w", comp.Name, err)
+		jsDestPath := componentJSPath(config, comp.Name)
+		if _, err := os.Stat(jsDestPath); err == nil {
+			lockedJS, err := lockedFileFor(jsDestPath, source.location(ref, componentJSRepoPath(comp.Name)))
+			if err != nil {
+				return err
+			}
+			locked.JS = &lockedJS
 		}
 	}
-
+	lock.setComponent(comp.Name, locked)
 	return nil
 }
 
-// installUtils handles the installation of required utility files.
-func installUtils(config Config, utilPaths []string, ref string, force bool) error {
-	if len(utilPaths) == 0 {
-		return nil
-	}
-
-	utilsBaseDestDir := config.UtilsDir
-	fmt.Printf("Ensuring utils are installed in: %s (from ref: %s)\n", utilsBaseDestDir, ref)
-	repoUtilBasePath := "internal/utils/"
-
-	// Ensure base utils directory exists.
-	err := os.MkdirAll(utilsBaseDestDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create base utils directory '%s': %w", utilsBaseDestDir, err)
-	}
-
-	for _, repoUtilPath := range utilPaths {
-		// Determine destination path, preserving subdirectory structure.
-		var destPath string
-		if strings.HasPrefix(repoUtilPath, repoUtilBasePath) {
-			relativePath := repoUtilPath[len(repoUtilBasePath):]
-			destPath = filepath.Join(utilsBaseDestDir, relativePath)
-		} else {
-			fmt.Printf("  Warning: Util path '%s' does not start with '%s'. Placing it directly in '%s'.\n", repoUtilPath, repoUtilBasePath, utilsBaseDestDir)
-			fileName := filepath.Base(repoUtilPath)
-			destPath = filepath.Join(utilsBaseDestDir, fileName)
-		}
-
-		// Ensure the specific util directory exists.
-		utilDestDir := filepath.Dir(destPath)
-		err := os.MkdirAll(utilDestDir, 0755)
-		if err != nil {
-			return fmt.Errorf("failed to create destination utils directory '%s': %w", utilDestDir, err)
-		}
-
-		// Check if file exists and handle overwrite logic.
-		fileExists := false
-		if _, err := os.Stat(destPath); err == nil {
-			fileExists = true
+// addScriptTemplateToFiles adds Script() template at the end of .templ files,
+// staging the updated files in tx (unused in dry-run mode).
+func addScriptTemplateToFiles(config Config, comp ComponentDef, jsFileName string, opts *installOptions, tx *installTransaction) error {
+	for _, repoFilePath := range comp.Files {
+		if !strings.HasSuffix(repoFilePath, ".templ") {
+			continue // Only process .templ files
 		}
 
-		shouldWriteFile := true
-
-		if fileExists {
-			existingRef, _ := readFileVersion(destPath)
-			if existingRef == ref {
-				fmt.Printf("  Info: Util file '%s' already up-to-date (ref: %s). Skipping.\n", destPath, ref)
-				shouldWriteFile = false
-			} else {
-				if force {
-					fmt.Printf("  Info: Util file '%s' exists (Version: '%s'). Forcing overwrite with ref '%s'.\n", destPath, existingRef, ref)
-				} else {
-					shouldOverwrite := askForOverwrite(destPath, existingRef, ref)
-					if !shouldOverwrite {
-						fmt.Printf("  Info: Skipping overwrite for '%s'.\n", destPath)
-						shouldWriteFile = false
-					}
+		// Determine the destination path
+		destPath, _ := componentDestPath(config, repoFilePath)
+		scriptAction := fileAction{Kind: "script", Name: comp.Name, Path: destPath, Action: actionAddScript}
+
+		// In dry-run mode, files about to be (re)written from the registry won't have a Script() template yet.
+		if opts.dryRun {
+			content, err := os.ReadFile(destPath)
+			switch opts.plannedAction(destPath) {
+			case actionCreate, actionOverwrite:
+				opts.record(scriptAction)
+			default:
+				if err == nil && !strings.Contains(string(content), "templ Script()") {
+					opts.record(scriptAction)
 				}
 			}
+			continue
 		}
 
-		if shouldWriteFile {
-			fileURL := rawContentBaseURL + ref + "/" + repoUtilPath
-			fmt.Printf("   Downloading util %s...\n", fileURL)
-			data, err := downloadFile(fileURL)
-			if err != nil {
-				fileNameForError := filepath.Base(repoUtilPath)
-				return fmt.Errorf("failed to download util '%s' from %s: %w", fileNameForError, fileURL, err)
+		// Use the staged content if the file is being written, otherwise the existing file.
+		content, ok := tx.staged(destPath)
+		if !ok {
+			var err error
+			content, err = os.ReadFile(destPath)
+			if os.IsNotExist(err) {
+				continue // Skip if .templ file doesn't exist
 			}
-
-			// Add version comment and replace imports.
-			utilNameForComment := filepath.Base(repoUtilPath)
-			versionComment := fmt.Sprintf("// templui util %s - version: %s installed by templui %s\n", utilNameForComment, ref, version)
-			modifiedData := append([]byte(versionComment), data...)
-			if strings.HasSuffix(repoUtilPath, ".go") {
-				modifiedData = replaceImports(modifiedData, config, "")
-				// Replace package name to match the destination directory name
-				targetPkgName := filepath.Base(utilsBaseDestDir)
-				modifiedData = bytes.Replace(modifiedData, []byte("package utils"), []byte("package "+targetPkgName), 1)
-			}
-
-			// Write the file.
-			err = os.WriteFile(destPath, modifiedData, 0644)
 			if err != nil {
-				return fmt.Errorf("failed to write util file '%s': %w", destPath, err)
-			}
-			if fileExists {
-				fmt.Printf("   Overwritten %s\n", destPath)
-			} else {
-				fmt.Printf("   Installed %s\n", destPath)
+				return fmt.Errorf("failed to read .templ file '%s': %w", destPath, err)
 			}
 		}
-	}
 
-	return nil
-}
-
-// installComponentJS handles the installation of JavaScript files for a component
-// and automatically adds Script() template at the end of .templ files
-func installComponentJS(config Config, comp ComponentDef, ref string, force bool) error {
-	jsFileName := comp.Name + ".min.js"
-	// Load from component directory instead of component_scripts
-	jsSourceURL := rawContentBaseURL + ref + "/internal/components/" + comp.Name + "/" + jsFileName
-	jsDestPath := filepath.Join(config.JSDir, jsFileName)
-
-	// Ensure JS directory exists
-	err := os.MkdirAll(config.JSDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create JS directory '%s': %w", config.JSDir, err)
-	}
-
-	// Check if JS file exists and handle overwrite logic
-	fileExists := false
-	if _, err := os.Stat(jsDestPath); err == nil {
-		fileExists = true
-	}
-
-	shouldWriteJS := true
-	if fileExists && !force {
-		fmt.Printf("   JavaScript file '%s' already exists. Overwrite? (y/N): ", jsDestPath)
-		var response string
-		fmt.Scanln(&response)
-		shouldWriteJS = strings.ToLower(strings.TrimSpace(response)) == "y"
-	}
-
-	if shouldWriteJS {
-		fmt.Printf("   Downloading JavaScript: %s\n", jsSourceURL)
-		jsData, err := downloadFile(jsSourceURL)
-		if err != nil {
-			return fmt.Errorf("failed to download JS file from %s: %w", jsSourceURL, err)
+		// Check if Script() template already exists
+		newContent, added := withScriptTemplate(content, config, jsFileName)
+		if !added {
+			fmt.Printf("   Script() template already exists in %s\n", destPath)
+			continue
 		}
 
-		err = os.WriteFile(jsDestPath, jsData, 0644)
+		err := tx.stage(destPath, newContent)
 		if err != nil {
-			return fmt.Errorf("failed to write JS file '%s': %w", jsDestPath, err)
-		}
-
-		if fileExists {
-			fmt.Printf("   Overwritten %s\n", jsDestPath)
-		} else {
-			fmt.Printf("   Installed %s\n", jsDestPath)
+			return err
 		}
-	}
 
-	// Add Script() template to .templ files
-	err = addScriptTemplateToFiles(config, comp, jsFileName)
-	if err != nil {
-		return fmt.Errorf("failed to add Script() template: %w", err)
+		opts.record(scriptAction)
+		fmt.Printf("   Adding Script() template to %s\n", destPath)
 	}
 
 	return nil
 }
 
-// addScriptTemplateToFiles adds Script() template at the end of .templ files
-func addScriptTemplateToFiles(config Config, comp ComponentDef, jsFileName string) error {
-	repoComponentBasePath := "internal/components/"
-
-	for _, repoFilePath := range comp.Files {
-		if !strings.HasSuffix(repoFilePath, ".templ") {
-			continue // Only process .templ files
-		}
+// withScriptTemplate appends the Script() template to the content of a .templ file.
+// It returns false if the file already defines a Script() template.
+func withScriptTemplate(content []byte, config Config, jsFileName string) ([]byte, bool) {
+	contentStr := string(content)
+	if strings.Contains(contentStr, "templ Script()") {
+		return content, false
+	}
 
-		// Determine the destination path
-		var destPath string
-		if strings.HasPrefix(repoFilePath, repoComponentBasePath) {
-			relativePath := repoFilePath[len(repoComponentBasePath):]
-			destPath = filepath.Join(config.ComponentsDir, relativePath)
-		} else {
-			fileName := filepath.Base(repoFilePath)
-			destPath = filepath.Join(config.ComponentsDir, fileName)
-		}
+	// Create the Script() template with correct templ syntax, nonce support, and cache busting
+	scriptTemplate := fmt.Sprintf(`templ Script() {
+	<script defer nonce={ templ.GetNonce(ctx) } src={ utils.ScriptURL("%s") }></script>
+}`, scriptWebPath(config, jsFileName))
 
-		// Check if file exists
-		if _, err := os.Stat(destPath); os.IsNotExist(err) {
-			continue // Skip if .templ file doesn't exist
-		}
+	// Add Script() template at the end
+	return []byte(strings.TrimSpace(contentStr) + "\n\n" + scriptTemplate + "\n"), true
+}
 
-		// Read the current file content
-		content, err := os.ReadFile(destPath)
-		if err != nil {
-			return fmt.Errorf("failed to read .templ file '%s': %w", destPath, err)
-		}
+// scriptWebPath returns the URL path a JavaScript file is served at.
+// Use jsPublicPath if set, otherwise fallback to "/" + jsDir
+func scriptWebPath(config Config, jsFileName string) string {
+	if config.JSPublicPath != "" {
+		// Use configured public path
+		return strings.TrimSuffix(config.JSPublicPath, "/") + "/" + jsFileName
+	}
+	// Fallback to jsDir (backward compatible)
+	return "/" + filepath.ToSlash(filepath.Join(config.JSDir, jsFileName))
+}
 
-		contentStr := string(content)
+// Directories of the components and utils within the repository.
+const (
+	repoComponentBasePath = "internal/components/"
+	repoUtilBasePath      = "internal/utils/"
+)
 
-		// Create the web path for the JavaScript file
-		// Use jsPublicPath if set, otherwise fallback to "/" + jsDir
-		var webPath string
-		if config.JSPublicPath != "" {
-			// Use configured public path
-			webPath = strings.TrimSuffix(config.JSPublicPath, "/") + "/" + jsFileName
-		} else {
-			// Fallback to jsDir (backward compatible)
-			webPath = "/" + filepath.ToSlash(filepath.Join(config.JSDir, jsFileName))
-		}
+// componentDestPath maps a component file path from the registry to its destination in the project,
+// honoring the directory mapped to the component and preserving subdirectory structure. It returns false if the path is not inside the components directory
+// of the repository, in which case the file is placed directly in the components directory of its registry.
+func componentDestPath(config Config, repoFilePath string) (string, bool) {
+	scope, repoFilePath := splitScope(repoFilePath)
+	componentsDir := filepath.Join(config.ComponentsDir, scopeDir(scope))
+	if strings.HasPrefix(repoFilePath, repoComponentBasePath) {
+		relativePath := repoFilePath[len(repoComponentBasePath):]
+		if componentName, rest, ok := strings.Cut(relativePath, "/"); ok {
+			return filepath.Join(config.componentDir(qualifiedName(scope, componentName)), rest), true
+		}
+		return filepath.Join(componentsDir, relativePath), true
+	}
+	return filepath.Join(componentsDir, filepath.Base(repoFilePath)), false
+}
 
-		// Check if Script() template already exists
-		if strings.Contains(contentStr, "templ Script()") {
-			fmt.Printf("   Script() template already exists in %s\n", destPath)
-			continue
+// renderComponentFile applies the transformations of an installation to a downloaded component file:
+// it prepends the version comment with the documentation link, adjusts import paths and applies the
+// package name mapped to the component. The returned flag reports whether any import path was adjusted.
+// Go and templ files that can't be parsed are reported, rather than installed with upstream import paths.
+func renderComponentFile(data []byte, config Config, comp ComponentDef, ref, repoFilePath string) ([]byte, bool, error) {
+	adjusted := false
+	if strings.HasSuffix(repoFilePath, ".templ") || strings.HasSuffix(repoFilePath, ".go") {
+		// Rewrite before adding the version comment, so parse errors point at the lines of the fetched file.
+		var err error
+		data, err = renamePackage(data, upstreamPackageName(comp.Name), config.componentPackage(comp.Name))
+		if err == nil {
+			scope, _ := splitScope(comp.Name)
+			data, adjusted, err = rewriteImports(data, config, scope)
 		}
-
-		// Create the Script() template with correct templ syntax, nonce support, and cache busting
-		scriptTemplate := fmt.Sprintf(`templ Script() {
-	<script defer nonce={ templ.GetNonce(ctx) } src={ utils.ScriptURL("%s") }></script>
-}`, webPath)
-
-		// Add Script() template at the end
-		newContent := strings.TrimSpace(contentStr) + "\n\n" + scriptTemplate + "\n"
-
-		// Write the updated content
-		err = os.WriteFile(destPath, []byte(newContent), 0644)
 		if err != nil {
-			return fmt.Errorf("failed to write updated .templ file '%s': %w", destPath, err)
+			return nil, false, fmt.Errorf("failed to rewrite '%s': %w", repoFilePath, err)
 		}
-
-		fmt.Printf("   Added Script() template to %s\n", destPath)
 	}
 
-	return nil
+	versionComment := fmt.Sprintf("// templui component %s - version: %s installed by templui %s\n", comp.Name, ref, version)
+	if docsURL := componentDocsURL(comp); docsURL != "" {
+		versionComment += fmt.Sprintf("// 📚 Documentation: %s\n", docsURL)
+	}
+	return append([]byte(versionComment), data...), adjusted, nil
 }
 
-// getInstalledComponentNames returns the names of all installed components
-// by listing subdirectories in the components directory.
-func getInstalledComponentNames(componentsDir string) ([]string, error) {
-	entries, err := os.ReadDir(componentsDir)
+// renderPristineComponentFile renders a component file exactly as a fresh installation would leave it,
+// including the Script() template for components with JavaScript.
+func renderPristineComponentFile(data []byte, config Config, comp ComponentDef, ref, repoFilePath string) ([]byte, error) {
+	rendered, _, err := renderComponentFile(data, config, comp, ref, repoFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read components directory '%s': %w", componentsDir, err)
+		return nil, err
+	}
+	if comp.HasJS && config.JSDir != "" && strings.HasSuffix(repoFilePath, ".templ") {
+		rendered, _ = withScriptTemplate(rendered, config, componentJSFile(comp.Name))
+	}
+	return rendered, nil
+}
+
+// getInstalledComponentNames returns the names of all installed components.
+// The lockfile is used if it records any components, otherwise the
+// subdirectories in the components directory are listed.
+func getInstalledComponentNames(config Config, lock *Lockfile) ([]string, error) {
+	if lock != nil && len(lock.Components) > 0 {
+		return lock.componentNames(), nil
+	}
+	entries, err := os.ReadDir(config.ComponentsDir)
+	if err != nil && len(config.Components) == 0 {
+		return nil, fmt.Errorf("failed to read components directory '%s': %w", config.ComponentsDir, err)
 	}
 	var names []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if !entry.IsDir() {
+			continue
+		}
+		// Components of named registries are installed in a directory named after the registry.
+		if scope := "@" + entry.Name(); namedRegistries[scope].URL != "" {
+			scopeEntries, _ := os.ReadDir(filepath.Join(config.ComponentsDir, entry.Name()))
+			for _, scopeEntry := range scopeEntries {
+				if name := qualifiedName(scope, scopeEntry.Name()); scopeEntry.IsDir() && config.Components[name].Dir == "" {
+					names = append(names, name)
+				}
+			}
+			continue
+		}
+		if config.Components[entry.Name()].Dir == "" {
 			names = append(names, entry.Name())
 		}
 	}
+	// Components mapped to another directory are installed if their directory exists.
+	for name, mapping := range config.Components {
+		if _, err := os.Stat(config.componentDir(name)); err == nil && mapping.Dir != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
 	return names, nil
 }
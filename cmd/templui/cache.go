@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// runCache handles the 'cache' command logic.
+func runCache(args []string) {
+	if len(args) == 0 {
+		failf("Error: No cache action specified.\n")
+		fmt.Println("Usage: templui cache list | prune [<ref>...] | prefetch[@<ref>]")
+		return
+	}
+
+	cache, err := openFileCache()
+	if err != nil {
+		failf("Error opening cache: %v\n", err)
+		return
+	}
+
+	action, ref, _ := strings.Cut(args[0], "@")
+	switch action {
+	case "list":
+		listCache(cache)
+	case "prune":
+		removed, err := cache.prune(args[1:])
+		if err != nil {
+			failf("Error pruning cache: %v\n", err)
+			return
+		}
+		fmt.Printf("🧹 Removed %d cached file(s) from %s\n", removed, cache.dir)
+	case "prefetch":
+		if ref == "" {
+			ref = getDefaultRef()
+		}
+		result.Ref = ref
+		prefetch(ref)
+	default:
+		failf("Error: Unknown cache action '%s'. Use 'list', 'prune' or 'prefetch'.\n", args[0])
+	}
+}
+
+// listCache prints the cached files grouped by ref.
+func listCache(cache *fileCache) {
+	entries := cache.entries()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Ref != entries[j].Ref {
+			return entries[i].Ref < entries[j].Ref
+		}
+		return entries[i].Location < entries[j].Location
+	})
+	result.Cached = entries
+
+	fmt.Printf("Cache directory: %s\n", cache.dir)
+	if len(entries) == 0 {
+		fmt.Println("The cache is empty.")
+		return
+	}
+
+	totalSize := 0
+	for i, entry := range entries {
+		if i == 0 || entries[i-1].Ref != entry.Ref {
+			immutable := ""
+			if isImmutableRef(entry.Ref) {
+				immutable = " (immutable)"
+			}
+			fmt.Printf("\n%s%s:\n", entry.Ref, immutable)
+		}
+		fmt.Printf("  - %-55s %8d bytes  %s\n", entry.Path, entry.Size, entry.FetchedAt.Local().Format("2006-01-02 15:04"))
+		totalSize += entry.Size
+	}
+	fmt.Printf("\n%d file(s), %d bytes\n", len(entries), totalSize)
+}
+
+// prefetch downloads the registry and all component, JavaScript and util files of a ref into the cache.
+func prefetch(ref string) {
+	if _, ok := sourceFor("").(*cachedSource); !ok {
+		failf("Error: The registry source %s is not cached (local directories are read directly, and 'prefetch' needs network access).\n", source.location(ref, ""))
+		return
+	}
+	if *offlineFlag {
+		failf("Error: Cannot prefetch in offline mode.\n")
+		return
+	}
+
+	fmt.Printf("🔍 Fetching component registry from ref '%s'...\n", ref)
+	registry, err := fetchRegistry(ref)
+	if err != nil {
+		failf("❌ Error fetching registry: %v\n", err)
+		return
+	}
+
+	var repoPaths []string
+	for _, comp := range registry.Components {
+		repoPaths = append(repoPaths, comp.Files...)
+		if comp.HasJS {
+			repoPaths = append(repoPaths, componentJSRepoPath(comp.Name))
+		}
+	}
+	for _, util := range registry.Utils {
+		repoPaths = append(repoPaths, util.Path)
+	}
+
+	fmt.Printf("⬇️  Prefetching %d file(s)...\n", len(repoPaths))
+	hashes := registry.FileHashes()
+	if len(hashes) == 0 {
+		fmt.Println("⚠️  The registry publishes no SHA-256 hashes, cached files can't be verified.")
+	}
+	for _, repoPath := range repoPaths {
+		_, err := fetchVerified(ref, repoPath, hashes)
+		if err != nil {
+			failf("❌ Error fetching %s: %v\n", repoPath, err)
+		} else if len(hashes) > 0 && hashes[repoPath] == "" {
+			fmt.Printf("⚠️  No SHA-256 published for %s, cached it unverified.\n", repoPath)
+		}
+	}
+	resolveCommit("", ref)
+	fmt.Printf("✅ Cached %d component(s) and %d util(s) for ref '%s'\n", len(registry.Components), len(registry.Utils), ref)
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes shared by all commands. Commands may define more specific codes (see diff, verify and outdated).
+const (
+	exitOK    = 0
+	exitError = 1 // The command failed
+	exitUsage = 2 // The command line is invalid
+)
+
+// commonFlags are accepted by every command.
+var commonFlags = []string{"registry", "offline", "output", "timeout"}
+
+// command is a subcommand of the CLI with its own flags.
+type command struct {
+	name       string
+	aliases    []string
+	args       string   // Arguments shown in the usage line, e.g. "<component>..."
+	summary    string   // One-line description
+	ref        bool     // Whether a ref is accepted as <name>@<ref> or with --ref
+	flags      []string // Names of the global flags accepted after the command, besides commonFlags
+	hidden     bool     // Left out of the help and suggestions
+	rawArgs    bool     // Arguments are passed on without parsing flags
+	noRegistry bool     // Doesn't use the registry, so .templui.json and the registry sources aren't read
+	run        func(inv invocation) int
+
+	// complete returns the completion candidates for the next argument, given the arguments so far (optional).
+	complete func(inv invocation) []string
+}
+
+// invocation is the parsed command line of a command.
+type invocation struct {
+	cmd  *command
+	args []string // Positional arguments after the command
+	ref  string   // Ref given as <name>@<ref> or with --ref, "" if none
+}
+
+// commands lists all subcommands in the order they are shown in the help.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name: "new", args: "<project-name>", summary: "Create a new templUI project", ref: true,
+			flags: []string{"force", "module", "dry-run"},
+			run: func(inv invocation) int {
+				runNew(inv.args, inv.ref, *forceOverwrite, *moduleFlag, *dryRunFlag)
+				return exitOK
+			},
+		},
+		{
+			name: "init", summary: "Initialize config and install utils from <ref>", ref: true,
+			flags: []string{"force", "dry-run"},
+			run: func(inv invocation) int {
+				runInit(inv.args, inv.ref, *forceOverwrite, *dryRunFlag)
+				return exitOK
+			},
+		},
+		{
+			name: "add", args: "<component>... | \"*\"", summary: "Add or update components and their dependencies from <ref>", ref: true,
+			flags: []string{"force", "installed", "merge", "dry-run", "keep-backups"},
+			run: func(inv invocation) int {
+				runAdd(inv.args, inv.ref, *forceOverwrite, *installedFlag, *mergeFlag, *dryRunFlag, *keepBackupsFlag)
+				return exitOK
+			},
+			complete: completeRegistryComponents,
+		},
+		{
+			name: "list", aliases: []string{"ls"}, args: "[@<registry>]", summary: "List available components and utils from <ref>", ref: true,
+			flags: []string{"category", "tag"},
+			run: func(inv invocation) int {
+				runList(inv.args, inv.ref, splitList(*categoryFlag), splitList(*tagFlag))
+				return exitOK
+			},
+		},
+		{
+			name: "search", args: "<query>", summary: "Search components by name, description and tags", ref: true,
+			flags: []string{"category", "tag"},
+			run: func(inv invocation) int {
+				return runSearch(inv.args, inv.ref, splitList(*categoryFlag), splitList(*tagFlag))
+			},
+		},
+		{
+			name: "info", args: "<component>", summary: "Show the full metadata, dependency tree and local installation of a component", ref: true,
+			run: func(inv invocation) int { return runInfo(inv.args, inv.ref) },
+			complete: func(inv invocation) []string {
+				if len(inv.args) > 0 {
+					return nil
+				}
+				return completeRegistryComponents(inv)
+			},
+		},
+		{
+			name: "remove", aliases: []string{"rm"}, args: "[<component>...]", summary: "Remove components and their JavaScript files",
+			flags: []string{"force", "prune"},
+			run: func(inv invocation) int {
+				runRemove(inv.args, *forceOverwrite, *pruneFlag)
+				return exitOK
+			},
+			complete: completeInstalledComponents,
+		},
+		{
+			name: "why", args: "<component>", summary: "Show why a component is installed",
+			run: func(inv invocation) int { return runWhy(inv.args) },
+			complete: func(inv invocation) []string {
+				if len(inv.args) > 0 {
+					return nil
+				}
+				return completeInstalledComponents(inv)
+			},
+		},
+		{
+			name: "graph", args: "[<component>...]", summary: "Print the dependency graph of <ref> (or of the installed components) as DOT or Mermaid", ref: true,
+			flags: []string{"format", "installed"},
+			run: func(inv invocation) int {
+				return runGraph(inv.args, inv.ref, *formatFlag, *installedFlag)
+			},
+			complete: completeRegistryComponents,
+		},
+		{
+			name: "diff", args: "[<component>...]", summary: "Show local changes against the installed version (or <ref>)", ref: true,
+			run:      func(inv invocation) int { return runDiff(inv.args, inv.ref) },
+			complete: completeInstalledComponents,
+		},
+		{
+			name: "verify", args: "[<component>...]", summary: "Check installed files for local edits and hash mismatches",
+			run:      func(inv invocation) int { return runVerify(inv.args) },
+			complete: completeInstalledComponents,
+		},
+		{
+			name: "status", args: "[<component>...]", summary: "Show the ref, local modifications and JavaScript of installed components and utils",
+			run:      func(inv invocation) int { return runStatus(inv.args) },
+			complete: completeInstalledComponents,
+		},
+		{
+			name: "outdated", args: "[<component>...]", summary: "Show what would change with the latest release (or <ref>)", ref: true,
+			run:      func(inv invocation) int { return runOutdated(inv.args, inv.ref) },
+			complete: completeInstalledComponents,
+		},
+		{
+			name: "doctor", summary: "Check the project setup, installed components and tools, and suggest fixes",
+			run: func(inv invocation) int { return runDoctor() },
+		},
+		{
+			name: "upgrade", summary: "Upgrade the CLI and utils to <ref> (default: latest)", ref: true,
+			flags: []string{"dry-run", "keep-backups", "go-install", "release-url"},
+			run: func(inv invocation) int {
+				runUpgrade(inv.args, inv.ref, *dryRunFlag, *keepBackupsFlag, *goInstallFlag, *releaseURLFlag)
+				return exitOK
+			},
+		},
+		{
+			name: "cache", args: "list | prune [<ref>...] | prefetch[@<ref>]", summary: "Manage the cache of registry and component files",
+			run: func(inv invocation) int {
+				runCache(inv.args)
+				return exitOK
+			},
+			complete: completeCache,
+		},
+		{
+			name: "registry", noRegistry: true, args: "build [<repository-dir>]", summary: "Generate and check registry.json from the components of a repository",
+			flags: []string{"check", "hashes", "force"},
+			run:   func(inv invocation) int { return runRegistry(inv.args, *checkFlag, *hashesFlag, *forceOverwrite) },
+			complete: func(inv invocation) []string {
+				if len(inv.args) > 0 {
+					return nil
+				}
+				return []string{"build"}
+			},
+		},
+		{
+			name: "completion", noRegistry: true, args: "bash|zsh|fish", summary: "Print the shell completion script for bash, zsh or fish",
+			run: func(inv invocation) int { return runCompletion(inv.args) },
+			complete: func(inv invocation) []string {
+				if len(inv.args) > 0 {
+					return nil
+				}
+				return []string{"bash", "zsh", "fish"}
+			},
+		},
+		{
+			name: "__complete", hidden: true, rawArgs: true, summary: "Print completion candidates for the completion scripts",
+			run: func(inv invocation) int { return runComplete(inv.args) },
+		},
+		{
+			name: "help", noRegistry: true, args: "[<command>]", summary: "Show help for the CLI or a command",
+			run:      func(inv invocation) int { return runHelp(inv.args) },
+			complete: completeCommands,
+		},
+		{
+			name: "version", noRegistry: true, summary: "Show the CLI version",
+			run: func(inv invocation) int {
+				result.Version = version
+				fmt.Printf("templUI %s\n", version)
+				return exitOK
+			},
+		},
+	}
+}
+
+// findCommand looks up a command by name or alias.
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// parseInvocation parses the command name, its optional @ref and the flags and arguments after it.
+// Flags may appear anywhere after the command, up to a "--" argument.
+func parseInvocation(args []string) (invocation, error) {
+	name, ref, hasRef := strings.Cut(args[0], "@")
+	cmd := findCommand(name)
+	if cmd == nil {
+		message := fmt.Sprintf("unknown command '%s'", name)
+		if suggestion := suggestCommand(name); suggestion != "" {
+			message += fmt.Sprintf(". Did you mean '%s'?", suggestion)
+		}
+		return invocation{}, errors.New(message)
+	}
+	inv := invocation{cmd: cmd, ref: ref}
+	if hasRef && !cmd.ref {
+		return inv, fmt.Errorf("'%s' does not accept a ref", cmd.name)
+	}
+	if hasRef && ref == "" {
+		return inv, fmt.Errorf("invalid format '%s'. Use '%s' or '%s@<ref>'", args[0], cmd.name, cmd.name)
+	}
+
+	if cmd.rawArgs {
+		inv.args = args[1:]
+		return inv, nil
+	}
+
+	fs := cmd.flagSet()
+	rest := args[1:]
+	for {
+		err := fs.Parse(rest)
+		if err != nil {
+			return inv, err
+		}
+		consumed := len(rest) - fs.NArg()
+		terminated := consumed > 0 && rest[consumed-1] == "--"
+		rest = fs.Args()
+		if terminated {
+			inv.args = append(inv.args, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		inv.args = append(inv.args, rest[0])
+		rest = rest[1:]
+	}
+
+	// Merge a ref given with --ref (or before the command) with <name>@<ref>.
+	if *refFlag != "" {
+		if !cmd.ref {
+			return inv, fmt.Errorf("'%s' does not accept a ref", cmd.name)
+		}
+		if hasRef && ref != *refFlag {
+			return inv, fmt.Errorf("conflicting refs '%s' and '%s'", ref, *refFlag)
+		}
+		inv.ref = *refFlag
+	}
+	return inv, nil
+}
+
+// flagSet returns the flag set of a command. Its flags share their values with the global flags,
+// so flags given before the command (templui --force add) keep working.
+func (c *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("templui "+c.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Errors are reported by the caller.
+	names := append([]string{}, c.flags...)
+	if c.ref {
+		names = append(names, "ref")
+	}
+	for _, name := range append(names, commonFlags...) {
+		f := flag.CommandLine.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	return fs
+}
+
+// printCommandHelp prints the usage, aliases and flags of a command.
+func printCommandHelp(cmd *command) {
+	usage := "templui " + cmd.name
+	if cmd.ref {
+		usage += "[@<ref>]"
+	}
+	usage += " [flags]"
+	if cmd.args != "" {
+		usage += " " + cmd.args
+	}
+	fmt.Printf("Usage: %s\n\n%s.\n", usage, cmd.summary)
+	if len(cmd.aliases) > 0 {
+		fmt.Printf("\nAliases: %s\n", strings.Join(cmd.aliases, ", "))
+	}
+	fmt.Println("\nFlags:")
+	fs := cmd.flagSet()
+	fs.SetOutput(os.Stdout)
+	fs.PrintDefaults()
+}
+
+// runHelp handles the 'help' command logic.
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		showHelp(nil, getDefaultRef())
+		return exitOK
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		failf("Error: Unknown command '%s'.\n", args[0])
+		return exitUsage
+	}
+	printCommandHelp(cmd)
+	return exitOK
+}
+
+// suggestCommand returns the command name or alias closest to a mistyped name, or "" if none is close.
+func suggestCommand(name string) string {
+	best, bestDistance := "", 3 // Suggest only names within an edit distance of 2.
+	for _, cmd := range commands {
+		if cmd.hidden {
+			continue
+		}
+		for _, candidate := range append([]string{cmd.name}, cmd.aliases...) {
+			if distance := levenshtein(name, candidate); distance < bestDistance {
+				best, bestDistance = candidate, distance
+			}
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
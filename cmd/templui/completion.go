@@ -0,0 +1,388 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// bashCompletion is the completion script for bash. Bash splits words at "@", so the
+// candidates are trimmed to the part after it.
+const bashCompletion = `# bash completion for templui
+# Load it with: source <(templui completion bash)
+_templui() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -r -a words <<< "$line"
+    [[ "$line" == *" " ]] && words+=("")
+    local cur="${words[${#words[@]}-1]}"
+    local IFS=$'\n'
+    COMPREPLY=($(templui __complete "${words[@]:1}" 2>/dev/null))
+    if [[ "$cur" == *@* && "$COMP_WORDBREAKS" == *@* ]]; then
+        local prefix="${cur%@*}@"
+        COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+    fi
+}
+complete -o default -F _templui templui
+`
+
+// zshCompletion is the completion script for zsh.
+const zshCompletion = `#compdef templui
+# zsh completion for templui
+# Load it with: source <(templui completion zsh)
+_templui() {
+    local -a completions
+    completions=(${(f)"$(templui __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)"})
+    if (( ${#completions} )); then
+        compadd -Q -- "${completions[@]}"
+    else
+        _files
+    fi
+}
+compdef _templui templui
+`
+
+// fishCompletion is the completion script for fish.
+const fishCompletion = `# fish completion for templui
+# Load it with: templui completion fish | source
+function __templui_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    templui __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c templui -f -a '(__templui_complete)'
+`
+
+// runCompletion handles the 'completion' command logic.
+func runCompletion(args []string) int {
+	if len(args) != 1 {
+		failf("Error: Specify a shell: 'bash', 'zsh' or 'fish'.\n")
+		fmt.Println("Usage: templui completion bash|zsh|fish")
+		return exitUsage
+	}
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		failf("Error: Unsupported shell '%s'. Use 'bash', 'zsh' or 'fish'.\n", args[0])
+		return exitUsage
+	}
+	return exitOK
+}
+
+// runComplete handles the hidden '__complete' command used by the completion scripts.
+// It prints the candidates for the last of the given words, one per line.
+func runComplete(words []string) int {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	// Progress messages of fetching the registry must not end up among the candidates.
+	out := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err == nil {
+		os.Stdout = devNull
+		defer devNull.Close()
+	}
+	candidates := completeWords(words)
+	os.Stdout = out
+
+	cur := words[len(words)-1]
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, cur) {
+			fmt.Fprintln(out, candidate)
+		}
+	}
+	return exitOK
+}
+
+// completeWords returns the completion candidates for the last word of a command line.
+func completeWords(words []string) []string {
+	cur := words[len(words)-1]
+	prev := words[:len(words)-1]
+
+	// Find the command and collect its arguments, skipping flags and their values.
+	var cmd *command
+	inv := invocation{}
+	for i := 0; i < len(prev); i++ {
+		word := prev[i]
+		if word == "--" {
+			inv.args = append(inv.args, prev[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(word, "-") {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f := flag.CommandLine.Lookup(name); f != nil && !isBoolFlag(f) && !hasValue && i+1 < len(prev) {
+				i++
+				value = prev[i]
+			}
+			switch name {
+			case "ref":
+				inv.ref = value
+			case "registry":
+				useCompletionRegistry(value)
+			}
+			continue
+		}
+		if cmd == nil {
+			name, ref, _ := strings.Cut(word, "@")
+			cmd = findCommand(name)
+			if cmd == nil {
+				return nil
+			}
+			if ref != "" {
+				inv.ref = ref
+			}
+			continue
+		}
+		inv.args = append(inv.args, word)
+	}
+	inv.cmd = cmd
+
+	// Complete the value of a flag.
+	if len(prev) > 0 && strings.HasPrefix(prev[len(prev)-1], "-") && !strings.Contains(prev[len(prev)-1], "=") {
+		if f := flag.CommandLine.Lookup(strings.TrimLeft(prev[len(prev)-1], "-")); f != nil && !isBoolFlag(f) {
+			switch f.Name {
+			case "output":
+				return []string{outputText, outputJSON}
+			case "ref":
+				return completionRefs()
+			case "format":
+				return []string{graphDOT, graphMermaid}
+			case "category", "tag":
+				return completeLabels(inv, f.Name)
+			}
+			return nil // Let the shell complete paths and free-form values.
+		}
+	}
+
+	// Complete flags.
+	if strings.HasPrefix(cur, "-") {
+		fs := flag.CommandLine
+		if cmd != nil {
+			fs = cmd.flagSet()
+		}
+		var names []string
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, "--"+f.Name)
+		})
+		return names
+	}
+
+	// Complete the command, or its ref.
+	if cmd == nil {
+		if name, _, hasRef := strings.Cut(cur, "@"); hasRef {
+			if c := findCommand(name); c != nil && c.ref {
+				var candidates []string
+				for _, ref := range completionRefs() {
+					candidates = append(candidates, name+"@"+ref)
+				}
+				return candidates
+			}
+			return nil
+		}
+		return completeCommands(invocation{})
+	}
+
+	if cmd.complete == nil {
+		return nil
+	}
+	return cmd.complete(inv)
+}
+
+// isBoolFlag reports whether a flag doesn't take a value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// useCompletionRegistry switches the registry source to one given on the command line being completed.
+func useCompletionRegistry(registry string) {
+	src, err := resolveRegistrySource(registry)
+	if err == nil {
+		src, err = withCache(src, false)
+	}
+	if err == nil {
+		source = src
+	}
+}
+
+// completionRefs returns the refs worth suggesting: the default ref, main and all cached refs.
+func completionRefs() []string {
+	refs := []string{getDefaultRef(), "main"}
+	for _, ref := range cachedRefs() {
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// cachedRefs returns the refs with files in the cache.
+func cachedRefs() []string {
+	cache, err := openFileCache()
+	if err != nil {
+		return nil
+	}
+	var refs []string
+	for _, entry := range cache.entries() {
+		if !slices.Contains(refs, entry.Ref) {
+			refs = append(refs, entry.Ref)
+		}
+	}
+	return refs
+}
+
+// completionRegistry returns the registry of the ref being completed, with the components of the named
+// registries at their configured refs. Completion never goes online, so remote registries are only read
+// from the cache. It returns false if no registry could be read.
+func completionRegistry(inv invocation) (Registry, bool) {
+	ref := inv.ref
+	if ref == "" {
+		ref = getDefaultRef()
+	}
+	src, ok := offlineSource(source)
+	if !ok {
+		return Registry{}, false
+	}
+
+	original := source
+	source = src
+	defer func() { source = original }()
+	registry, err := fetchRegistry(ref)
+	found := err == nil
+	for _, scope := range slices.Sorted(maps.Keys(namedRegistries)) {
+		named, err := fetchNamedRegistry(scope, namedRegistryRef(scope))
+		if err != nil {
+			continue
+		}
+		registry.Components = append(registry.Components, named.Components...)
+		found = true
+	}
+	return registry, found
+}
+
+// offlineSource returns a registry source that reads without network access: cached remote sources
+// in offline mode and local directories as they are. Uncached remote sources have none.
+func offlineSource(src registrySource) (registrySource, bool) {
+	switch src := src.(type) {
+	case *cachedSource:
+		offline := *src
+		offline.offline = true
+		return &offline, true
+	case dirSource:
+		return src, true
+	case *scopedSource:
+		defaultSource, ok := offlineSource(src.defaultSource)
+		if !ok {
+			return nil, false
+		}
+		scoped := &scopedSource{defaultSource: defaultSource, scopes: make(map[string]registrySource)}
+		for scope, scopeSource := range src.scopes {
+			scoped.scopes[scope], ok = offlineSource(scopeSource)
+			if !ok {
+				return nil, false
+			}
+		}
+		return scoped, true
+	default:
+		return nil, false
+	}
+}
+
+// completeRegistryComponents suggests the components of the registry of the ref being completed,
+// skipping those already given.
+func completeRegistryComponents(inv invocation) []string {
+	registry, ok := completionRegistry(inv)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, comp := range registry.Components {
+		if !slices.Contains(inv.args, comp.Name) {
+			names = append(names, comp.Name)
+		}
+	}
+	return names
+}
+
+// completeLabels suggests the categories or tags used in the registry of the ref being completed.
+func completeLabels(inv invocation, kind string) []string {
+	registry, ok := completionRegistry(inv)
+	if !ok {
+		return nil
+	}
+	var labels []string
+	for _, comp := range registry.Components {
+		values := comp.Categories
+		if kind == "tag" {
+			values = comp.Tags
+		}
+		for _, value := range values {
+			if !slices.Contains(labels, value) {
+				labels = append(labels, value)
+			}
+		}
+	}
+	slices.Sort(labels)
+	return labels
+}
+
+// completeInstalledComponents suggests the installed components, skipping those already given.
+func completeInstalledComponents(inv invocation) []string {
+	config, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		return nil
+	}
+	installed, err := getInstalledComponentNames(config, lock)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range installed {
+		if !slices.Contains(inv.args, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// completeCache suggests the actions of the 'cache' command and the refs they accept.
+func completeCache(inv invocation) []string {
+	if len(inv.args) == 0 {
+		candidates := []string{"list", "prune", "prefetch"}
+		for _, ref := range completionRefs() {
+			candidates = append(candidates, "prefetch@"+ref)
+		}
+		return candidates
+	}
+	if inv.args[0] == "prune" {
+		return cachedRefs()
+	}
+	return nil
+}
+
+// completeCommands suggests command names for 'help'.
+func completeCommands(inv invocation) []string {
+	if len(inv.args) > 0 {
+		return nil
+	}
+	var names []string
+	for _, c := range commands {
+		if !c.hidden {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCompletionRegistryStaysOffline(t *testing.T) {
+	server, requests := newStatusServer(t, status(http.StatusOK))
+	t.Setenv("TEMPLUI_CACHE_DIR", t.TempDir())
+	cached, err := withCache(httpSource{baseURL: server.URL + "/"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, src := range map[string]registrySource{
+		"uncached": httpSource{baseURL: server.URL + "/"},
+		"cached":   cached,
+	} {
+		t.Run(name, func(t *testing.T) {
+			useSource(t, src)
+			if _, ok := completionRegistry(invocation{ref: "v1.0.0"}); ok {
+				t.Error("completionRegistry() found a registry that isn't cached")
+			}
+			if got := requests.Load(); got != 0 {
+				t.Errorf("completion sent %d requests, want none", got)
+			}
+		})
+	}
+}
+
+func TestCompletionRegistryIncludesNamedRegistries(t *testing.T) {
+	registryJSON := func(name string) string {
+		return `{"schemaVersion": 1, "components": [{"name": "` + name + `", "slug": "` + name + `", "displayName": "", "description": "", ` +
+			`"files": ["internal/components/` + name + `/` + name + `.templ"], "dependencies": [], "categories": [], "tags": []}], "utils": []}`
+	}
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"default/" + registryPath: registryJSON("button"),
+		"acme/" + registryPath:    registryJSON("datagrid"),
+	})
+	previous := namedRegistries
+	namedRegistries = map[string]NamedRegistry{"@acme": {URL: filepath.Join(root, "acme")}}
+	t.Cleanup(func() { namedRegistries = previous })
+	src, err := withNamedRegistries(dirSource{root: filepath.Join(root, "default")}, namedRegistries, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	useSource(t, src)
+
+	got := completeRegistryComponents(invocation{args: []string{"button"}})
+	if want := []string{"@acme/datagrid"}; !slices.Equal(got, want) {
+		t.Errorf("completeRegistryComponents() = %v, want %v", got, want)
+	}
+	got = completeRegistryComponents(invocation{})
+	if want := []string{"button", "@acme/datagrid"}; !slices.Equal(got, want) {
+		t.Errorf("completeRegistryComponents() = %v, want %v", got, want)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"go/token"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -16,6 +18,71 @@ type Config struct {
 	ModuleName    string `json:"moduleName"`
 	JSDir         string `json:"jsDir,omitempty"`        // Directory for component JavaScript files
 	JSPublicPath  string `json:"jsPublicPath,omitempty"` // Public path where JS files are served (e.g., "/app/assets/js")
+	Registry      string `json:"registry,omitempty"`     // Registry source: local directory, file:// URL or HTTP base URL
+
+	// RegistryHeaders are HTTP headers sent with every request to the registry source, e.g. for
+	// authentication. Environment variables are expanded.
+	RegistryHeaders map[string]string `json:"registryHeaders,omitempty"`
+
+	// Registries are additional registries, keyed by name (e.g., "@acme"). Their components are
+	// installed with the name as prefix (e.g., "templui add @acme/datagrid").
+	Registries map[string]NamedRegistry `json:"registries,omitempty"`
+
+	// Components overrides the directory and package name of individual components, keyed by component name.
+	Components map[string]ComponentMapping `json:"components,omitempty"`
+}
+
+// ComponentMapping overrides where a component is installed and how its package is named.
+type ComponentMapping struct {
+	Dir     string `json:"dir,omitempty"`     // Directory relative to the project root (default: <componentsDir>/<name>)
+	Package string `json:"package,omitempty"` // Package name (default: the package name used by templUI)
+}
+
+// NamedRegistry is an additional registry configured in .templui.json.
+type NamedRegistry struct {
+	URL          string            `json:"url"`                    // Local directory, file:// URL or HTTP base URL, like "registry"
+	Ref          string            `json:"ref,omitempty"`          // Ref to install from (default: "main")
+	Headers      map[string]string `json:"headers,omitempty"`      // HTTP headers sent with every request, environment variables are expanded
+	ImportPrefix string            `json:"importPrefix,omitempty"` // Import path prefix of the internal packages of the registry's repository (default: templUI's)
+	Docs         string            `json:"docs,omitempty"`         // Base URL of the component documentation
+}
+
+// componentDir returns the directory a component is installed to. Components of
+// named registries are installed below a directory named after the registry (e.g., "components/acme/datagrid").
+func (c Config) componentDir(name string) string {
+	if mapping := c.Components[name]; mapping.Dir != "" {
+		return filepath.Clean(mapping.Dir)
+	}
+	scope, name := splitScope(name)
+	return filepath.Join(c.ComponentsDir, scopeDir(scope), name)
+}
+
+// utilsDir returns the directory the utils of a registry are installed to ("" for the default registry).
+func (c Config) utilsDir(scope string) string {
+	return filepath.Join(c.UtilsDir, scopeDir(scope))
+}
+
+// componentPackage returns the package name of an installed component.
+func (c Config) componentPackage(name string) string {
+	if mapping := c.Components[name]; mapping.Package != "" {
+		return mapping.Package
+	}
+	return upstreamPackageName(name)
+}
+
+// componentImportPath returns the import path of an installed component.
+func (c Config) componentImportPath(name string) string {
+	return c.ModuleName + "/" + filepath.ToSlash(c.componentDir(name))
+}
+
+// upstreamPackageName returns the package name of a component in the templUI repository: its name,
+// or its name with a "comp" suffix if that is a Go keyword (e.g., "switchcomp").
+func upstreamPackageName(name string) string {
+	_, name = splitScope(name)
+	if token.IsKeyword(name) {
+		return name + "comp"
+	}
+	return name
 }
 
 // loadConfig reads and parses the .templui.json configuration file.
@@ -64,10 +131,32 @@ func loadConfig() (Config, error) {
 		for _, field := range missingFields {
 			errorMsg.WriteString(fmt.Sprintf("   • %s\n", field))
 		}
-		errorMsg.WriteString("\n🔧 To fix this, run: templui -f init")
+		errorMsg.WriteString("\n🔧 To fix this, run: templui init --force")
 		return config, fmt.Errorf("%s", errorMsg.String())
 	}
 
+	// Validate component mappings: each component needs its own directory and a valid package name.
+	componentDirs := make(map[string]string)
+	for name, mapping := range config.Components {
+		if mapping.Package != "" && (!token.IsIdentifier(mapping.Package) || token.IsKeyword(mapping.Package)) {
+			return config, fmt.Errorf("invalid package name '%s' for component '%s' in %s", mapping.Package, name, configFileName)
+		}
+		dir := config.componentDir(name)
+		if other, ok := componentDirs[dir]; ok {
+			return config, fmt.Errorf("components '%s' and '%s' are both mapped to directory '%s' in %s", other, name, dir, configFileName)
+		}
+		componentDirs[dir] = name
+	}
+
+	for name, registry := range config.Registries {
+		if !registryNameRegex.MatchString(name) {
+			return config, fmt.Errorf("invalid registry name '%s' in %s: use '@' followed by a lowercase Go identifier (e.g., '@acme')", name, configFileName)
+		}
+		if registry.URL == "" {
+			return config, fmt.Errorf("registry '%s' in %s has no url", name, configFileName)
+		}
+	}
+
 	return config, nil
 }
 
@@ -84,6 +173,34 @@ func saveConfig(config Config) error {
 	return nil
 }
 
+// readConfiguredRegistry returns the "registry" and "registryHeaders" fields of .templui.json, if any.
+// The config is read leniently since the registry source is needed before
+// the config is validated (or even created, e.g., for 'init' and 'new').
+func readConfiguredRegistry() (string, map[string]string) {
+	data, err := os.ReadFile(configFileName)
+	if err != nil {
+		return "", nil
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", nil
+	}
+	return config.Registry, config.RegistryHeaders
+}
+
+// readConfiguredRegistries returns the named registries of .templui.json, read as leniently as readConfiguredRegistry.
+func readConfiguredRegistries() map[string]NamedRegistry {
+	data, err := os.ReadFile(configFileName)
+	if err != nil {
+		return nil
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	return config.Registries
+}
+
 // detectModuleName tries to read the module name from go.mod.
 func detectModuleName() string {
 	data, err := os.ReadFile("go.mod")
@@ -100,6 +217,30 @@ func detectModuleName() string {
 	return string(matches[1])
 }
 
+// defaultConfig fills the empty fields of a config with the defaults offered by promptForConfig.
+func defaultConfig(existingConfig *Config) Config {
+	config := Config{}
+	if existingConfig != nil {
+		config = *existingConfig
+	}
+	if config.ComponentsDir == "" {
+		config.ComponentsDir = "components"
+	}
+	if config.UtilsDir == "" {
+		config.UtilsDir = "utils"
+	}
+	if config.ModuleName == "" {
+		config.ModuleName = detectModuleName()
+	}
+	if config.JSDir == "" {
+		config.JSDir = "assets/js"
+	}
+	if config.JSPublicPath == "" {
+		config.JSPublicPath = "/" + config.JSDir
+	}
+	return config
+}
+
 // promptForConfig interactively prompts user for configuration values
 func promptForConfig(existingConfig *Config) Config {
 	reader := bufio.NewReader(os.Stdin)
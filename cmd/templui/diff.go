@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Exit codes of the 'diff' command, following diff(1).
+const (
+	diffExitSame    = 0
+	diffExitChanged = 1
+	diffExitError   = 2
+)
+
+// runDiff handles the 'diff' command logic and returns the exit code.
+func runDiff(args []string, targetRef string) int {
+	result.Ref = targetRef
+
+	config, err := loadConfig()
+	if err != nil {
+		failf("Error loading config: %v\n", err)
+		return diffExitError
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return diffExitError
+	}
+
+	// Without explicit components, compare everything that is installed.
+	componentNames := args
+	if len(componentNames) == 0 {
+		componentNames, err = getInstalledComponentNames(config, lock)
+		if err != nil {
+			failf("Error detecting installed components: %v\n", err)
+			return diffExitError
+		}
+	}
+
+	registries := make(map[string]Registry) // Fetched registries by registry name and ref.
+	exitCode := diffExitSame
+	for _, componentName := range componentNames {
+		ref := targetRef
+		if ref == "" {
+			ref, err = installedComponentRef(config, lock, componentName)
+			if err != nil {
+				failf("Error: %v\n", err)
+				exitCode = diffExitError
+				continue
+			}
+		}
+
+		scope, _ := splitScope(componentName)
+		registry, ok := registries[qualifiedName(scope, ref)]
+		if !ok {
+			registry, err = fetchRegistryFor(componentName, ref)
+			if err != nil {
+				failf("Error fetching registry for ref '%s': %v\n", ref, err)
+				exitCode = diffExitError
+				continue
+			}
+			registries[qualifiedName(scope, ref)] = registry
+		}
+
+		comp, ok := findComponent(registry, componentName)
+		if !ok {
+			failf("Error: Component '%s' not found in registry for ref '%s'.\n", componentName, ref)
+			exitCode = diffExitError
+			continue
+		}
+
+		changed, err := diffComponent(config, comp, ref)
+		if err != nil {
+			failf("Error comparing component '%s': %v\n", componentName, err)
+			exitCode = diffExitError
+			continue
+		}
+		if changed && exitCode == diffExitSame {
+			exitCode = diffExitChanged
+		}
+	}
+
+	return exitCode
+}
+
+// diffComponent prints a unified diff between the pristine files of a component at ref and the local files.
+// It reports whether any file differs.
+func diffComponent(config Config, comp ComponentDef, ref string) (bool, error) {
+	changed := false
+
+	for _, repoFilePath := range comp.Files {
+		destPath, _ := componentDestPath(config, repoFilePath)
+		data, err := fetchVerified(ref, repoFilePath, comp.Hashes)
+		if err != nil {
+			return changed, err
+		}
+		local, localName, err := readLocalForDiff(destPath)
+		if err != nil {
+			return changed, err
+		}
+
+		pristine, err := renderPristineComponentFile(data, config, comp, ref, repoFilePath)
+		if err != nil {
+			return changed, err
+		}
+		pristine = alignVersionComment(pristine, local)
+		patch := unifiedDiff(filepath.ToSlash(destPath)+"\t"+ref, localName+"\tlocal", string(pristine), string(local))
+		if patch != "" {
+			fmt.Print(patch)
+			result.Diffs = append(result.Diffs, fileDiff{Component: comp.Name, Path: filepath.ToSlash(destPath), Ref: ref, Diff: patch})
+			changed = true
+		}
+	}
+
+	if comp.HasJS && config.JSDir != "" {
+		jsDestPath := componentJSPath(config, comp.Name)
+		data, err := fetchVerified(ref, componentJSRepoPath(comp.Name), comp.Hashes)
+		if err != nil {
+			return changed, err
+		}
+		local, localName, err := readLocalForDiff(jsDestPath)
+		if err != nil {
+			return changed, err
+		}
+		patch := unifiedDiff(filepath.ToSlash(jsDestPath)+"\t"+ref, localName+"\tlocal", string(data), string(local))
+		if patch != "" {
+			fmt.Print(patch)
+			result.Diffs = append(result.Diffs, fileDiff{Component: comp.Name, Path: filepath.ToSlash(jsDestPath), Ref: ref, Diff: patch})
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// readLocalForDiff reads a local file for comparison. Missing files are treated
+// as empty and named /dev/null, like deleted files in a unified diff.
+func readLocalForDiff(path string) ([]byte, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "/dev/null", nil
+		}
+		return nil, "", fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	return data, filepath.ToSlash(path), nil
+}
+
+// installedComponentRef returns the ref an installed component was installed from,
+// using the lockfile and falling back to the version comment of its files.
+func installedComponentRef(config Config, lock *Lockfile, componentName string) (string, error) {
+	if locked, ok := lock.Components[componentName]; ok {
+		return locked.Ref, nil
+	}
+
+	compDir := config.componentDir(componentName)
+	entries, err := os.ReadDir(compDir)
+	if err != nil {
+		return "", fmt.Errorf("component '%s' is not installed in '%s'", componentName, compDir)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ref, err := readFileVersion(filepath.Join(compDir, entry.Name()))
+		if err == nil && ref != "" {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the installed version of component '%s'. Use 'diff@<ref>' to compare against a specific ref", componentName)
+}
+
+// findComponent looks up a component by name in the registry.
+func findComponent(registry Registry, name string) (ComponentDef, bool) {
+	for _, comp := range registry.Components {
+		if comp.Name == name {
+			return comp, true
+		}
+	}
+	return ComponentDef{}, false
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// States of a doctor check.
+const (
+	doctorOK      = "ok"
+	doctorWarning = "warning"
+	doctorError   = "error"
+)
+
+// templToolRegex matches the tool directive that makes templ available as 'go tool templ'.
+var templToolRegex = regexp.MustCompile(`(?m)^\s*tool\s+(\(\s*)?[^)]*github\.com/a-h/templ/cmd/templ`)
+
+// doctorCheck is the outcome of a single check of the 'doctor' command.
+type doctorCheck struct {
+	Check   string `json:"check"` // "config", "tools", "imports", "javascript" or "utils"
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // Suggested command or change
+}
+
+// doctor collects the outcome of the checks.
+type doctor struct {
+	checks []doctorCheck
+}
+
+func (d *doctor) report(check, status, message, fix string) {
+	d.checks = append(d.checks, doctorCheck{Check: check, Status: status, Message: message, Fix: fix})
+}
+
+// runDoctor handles the 'doctor' command logic. It returns exitError if any check failed.
+func runDoctor() int {
+	d := &doctor{}
+	d.checkTools()
+	if config, ok := d.checkConfig(); ok {
+		lock, err := loadLockfile()
+		if err != nil {
+			d.report("config", doctorError, fmt.Sprintf("%s can't be read: %v", lockFileName, err), "Fix or delete "+lockFileName+", then run 'templui add --installed'.")
+		} else {
+			d.checkComponents(config, lock)
+		}
+	}
+
+	result.Checks = d.checks
+	return printDoctorChecks(d.checks)
+}
+
+// checkTools checks that the go and templ binaries are available.
+func (d *doctor) checkTools() {
+	if path, err := exec.LookPath("go"); err != nil {
+		d.report("tools", doctorError, "go is not on PATH", "Install Go from https://go.dev/dl/.")
+	} else {
+		d.report("tools", doctorOK, "go found at "+path, "")
+	}
+
+	if path, err := exec.LookPath("templ"); err == nil {
+		d.report("tools", doctorOK, "templ found at "+path, "")
+		return
+	}
+	if data, err := os.ReadFile("go.mod"); err == nil && templToolRegex.Match(data) {
+		d.report("tools", doctorOK, "templ is available as 'go tool templ'", "")
+		return
+	}
+	d.report("tools", doctorError, "templ is not on PATH and not a tool of go.mod",
+		"Run 'go get -tool github.com/a-h/templ/cmd/templ@latest' or 'go install github.com/a-h/templ/cmd/templ@latest'.")
+}
+
+// checkConfig checks that the config can be loaded and that its module name matches go.mod.
+func (d *doctor) checkConfig() (Config, bool) {
+	config, err := loadConfig()
+	if err != nil {
+		d.report("config", doctorError, fmt.Sprintf("%s can't be loaded: %v", configFileName, err), "Run 'templui init'.")
+		return config, false
+	}
+	d.report("config", doctorOK, configFileName+" is valid", "")
+
+	if _, err := os.Stat("go.mod"); err != nil {
+		d.report("config", doctorError, "go.mod not found in the current directory", "Run templui from the root of your Go module.")
+		return config, true
+	}
+	moduleName := detectModuleName()
+	if moduleName != config.ModuleName {
+		d.report("config", doctorError, fmt.Sprintf("moduleName '%s' doesn't match the module '%s' in go.mod", config.ModuleName, moduleName),
+			fmt.Sprintf("Set \"moduleName\": %q in %s.", moduleName, configFileName))
+	} else {
+		d.report("config", doctorOK, "moduleName matches go.mod", "")
+	}
+
+	if config.JSPublicPath != "" && !strings.HasPrefix(config.JSPublicPath, "/") && !strings.Contains(config.JSPublicPath, "://") {
+		d.report("javascript", doctorWarning, fmt.Sprintf("jsPublicPath '%s' is relative, so scripts only load on top-level pages", config.JSPublicPath),
+			fmt.Sprintf("Set \"jsPublicPath\": %q in %s and run 'templui add --installed --force'.", "/"+config.JSPublicPath, configFileName))
+	}
+	return config, true
+}
+
+// checkComponents checks the imports, JavaScript and util versions of the installed components.
+func (d *doctor) checkComponents(config Config, lock *Lockfile) {
+	names, err := getInstalledComponentNames(config, lock)
+	if err != nil {
+		d.report("imports", doctorWarning, fmt.Sprintf("could not detect installed components: %v", err), "")
+		return
+	}
+
+	registryAt := registryLookup()
+	refs := make(map[string]string) // Installed ref by component name.
+	brokenImports, jsProblems := 0, 0
+	for _, name := range names {
+		ref, _ := installedComponentRef(config, lock, name)
+		refs[name] = ref
+		files := installedComponentFiles(config, lock, name)
+		brokenImports += d.checkImports(config, files)
+
+		var comp ComponentDef
+		if registry := registryAt(name, ref); registry != nil {
+			comp, _ = findComponent(*registry, name)
+		}
+		locked, isLocked := lock.Components[name]
+		if comp.HasJS || (isLocked && locked.JS != nil) {
+			jsProblems += d.checkJavaScript(config, lock, name, files)
+		}
+	}
+	if brokenImports == 0 && len(names) > 0 {
+		d.report("imports", doctorOK, fmt.Sprintf("all imports of %d installed component(s) resolve", len(names)), "")
+	}
+	if jsProblems == 0 && len(names) > 0 {
+		d.report("javascript", doctorOK, "all components with JavaScript have their script and Script() template", "")
+	}
+
+	d.checkUtils(config, lock, refs, registryAt)
+}
+
+// checkImports checks that the imports of a component's files within the project resolve to existing packages.
+// It returns the number of problems found.
+func (d *doctor) checkImports(config Config, files []string) int {
+	problems := 0
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".templ") && !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.FromSlash(file))
+		if err != nil {
+			d.report("imports", doctorError, fmt.Sprintf("%s is missing", file), "Run 'templui add --installed --force' to restore it.")
+			problems++
+			continue
+		}
+		_, parsed, err := parseFileHeader(data)
+		if err != nil {
+			d.report("imports", doctorWarning, fmt.Sprintf("%s can't be parsed: %v", file, err), "")
+			problems++
+			continue
+		}
+		for _, spec := range parsed.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if strings.HasPrefix(importPath, internalImportPrefix) {
+				d.report("imports", doctorError, fmt.Sprintf("%s imports the templUI repository package %s", file, importPath),
+					"Run 'templui add --installed --force' to rewrite its imports.")
+				problems++
+				continue
+			}
+			dir, ok := strings.CutPrefix(importPath, config.ModuleName+"/")
+			if !ok || isPackageDir(dir) {
+				continue
+			}
+			fix := fmt.Sprintf("Check componentsDir, utilsDir and components in %s.", configFileName)
+			if componentName, _, ok := installedComponentImport(config, importPath); ok {
+				fix = fmt.Sprintf("Run 'templui add %s'.", componentName)
+			}
+			d.report("imports", doctorError, fmt.Sprintf("%s imports %s, which doesn't exist", file, importPath), fix)
+			problems++
+		}
+	}
+	return problems
+}
+
+// isPackageDir reports whether a directory contains Go or templ files.
+func isPackageDir(dir string) bool {
+	entries, err := os.ReadDir(filepath.FromSlash(dir))
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(entries, func(entry os.DirEntry) bool {
+		return !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), ".templ"))
+	})
+}
+
+// checkJavaScript checks that a component needing JavaScript has its script file and a Script() template
+// loading it from the configured path. It returns the number of problems found.
+func (d *doctor) checkJavaScript(config Config, lock *Lockfile, name string, files []string) int {
+	problems := 0
+	if config.JSDir == "" {
+		d.report("javascript", doctorError, fmt.Sprintf("%s needs JavaScript, but jsDir is not set", name),
+			fmt.Sprintf("Set \"jsDir\" in %s and run 'templui add --force %s'.", configFileName, name))
+		return 1
+	}
+	jsFileName := componentJSFile(name)
+	jsPath := filepath.ToSlash(componentJSPath(config, name))
+	if locked, ok := lock.Components[name]; ok && locked.JS != nil {
+		jsPath = locked.JS.Path
+	}
+	if _, err := os.Stat(filepath.FromSlash(jsPath)); err != nil {
+		d.report("javascript", doctorError, fmt.Sprintf("%s needs %s, which is missing", name, jsPath), fmt.Sprintf("Run 'templui add --force %s'.", name))
+		problems++
+	}
+
+	var script string
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.FromSlash(file))
+		if err == nil && strings.Contains(string(data), "templ Script()") {
+			script = string(data)
+			break
+		}
+	}
+	webPath := scriptWebPath(config, jsFileName)
+	switch {
+	case script == "":
+		d.report("javascript", doctorError, fmt.Sprintf("%s has no Script() template, so its JavaScript is never loaded", name),
+			fmt.Sprintf("Run 'templui add --force %s' and render @%s.Script() in your layout.", name, config.componentPackage(name)))
+		problems++
+	case !strings.Contains(script, strconv.Quote(webPath)):
+		d.report("javascript", doctorWarning, fmt.Sprintf("the Script() template of %s doesn't load %s (jsPublicPath changed?)", name, webPath),
+			fmt.Sprintf("Run 'templui add --force %s' to regenerate it.", name))
+		problems++
+	}
+	return problems
+}
+
+// checkUtils checks that the installed utils come from the same ref as the components that use them.
+func (d *doctor) checkUtils(config Config, lock *Lockfile, refs map[string]string, registryAt func(name, ref string) *Registry) {
+	utils, err := installedUtils(config, lock)
+	if err != nil {
+		d.report("utils", doctorWarning, fmt.Sprintf("could not detect installed utils: %v", err), "")
+		return
+	}
+	if len(utils) == 0 {
+		if len(refs) > 0 {
+			d.report("utils", doctorError, "no utils are installed", "Run 'templui init' to install them.")
+		}
+		return
+	}
+
+	problems := 0
+	for _, util := range utils {
+		utilRef := ""
+		if util.locked != nil {
+			utilRef = util.locked.Ref
+		} else {
+			utilRef, _ = readFileVersion(filepath.FromSlash(util.path))
+		}
+
+		// Compare with the components of the same registry requiring the util, or with all if the requirements are unknown.
+		utilScope, _ := splitScope(util.repoPath)
+		var mismatched []string
+		mismatchedRef := ""
+		for _, name := range slices.Sorted(maps.Keys(refs)) {
+			ref := refs[name]
+			if scope, _ := splitScope(name); scope != utilScope {
+				continue
+			}
+			if ref == "" || ref == utilRef || !componentRequiresUtil(lock, name, ref, util.repoPath, registryAt) {
+				continue
+			}
+			mismatched = append(mismatched, name)
+			if mismatchedRef == "" {
+				mismatchedRef = ref
+			}
+		}
+		if len(mismatched) == 0 {
+			continue
+		}
+		d.report("utils", doctorWarning, fmt.Sprintf("%s is from '%s', but %s were installed from another ref", util.path, utilRef, strings.Join(mismatched, ", ")),
+			fmt.Sprintf("Run 'templui upgrade@%s' to install matching utils, or 'templui add@%s --installed' to update the components.", mismatchedRef, utilRef))
+		problems++
+	}
+	if problems == 0 {
+		d.report("utils", doctorOK, "utils match the ref of the installed components", "")
+	}
+}
+
+// componentRequiresUtil reports whether a component requires a util. Without known requirements,
+// every component is assumed to use every util.
+func componentRequiresUtil(lock *Lockfile, name, ref, repoUtilPath string, registryAt func(name, ref string) *Registry) bool {
+	var required []string
+	if locked, ok := lock.Components[name]; ok {
+		required = locked.RequiredUtils
+	} else if registry := registryAt(name, ref); registry != nil {
+		if comp, ok := findComponent(*registry, name); ok {
+			required = comp.RequiredUtils
+		}
+	}
+	return len(required) == 0 || slices.Contains(required, repoUtilPath)
+}
+
+// printDoctorChecks prints the checks with their fixes and returns the exit code.
+func printDoctorChecks(checks []doctorCheck) int {
+	errors, warnings := 0, 0
+	for _, c := range checks {
+		switch c.Status {
+		case doctorOK:
+			fmt.Printf("✅ %s\n", c.Message)
+		case doctorWarning:
+			fmt.Printf("⚠️  %s\n", c.Message)
+			warnings++
+		case doctorError:
+			fmt.Printf("❌ %s\n", c.Message)
+			errors++
+		}
+		if c.Fix != "" {
+			fmt.Printf("   💡 %s\n", c.Fix)
+		}
+	}
+
+	if errors == 0 && warnings == 0 {
+		fmt.Println("\n🩺 Everything looks good.")
+		return exitOK
+	}
+	fmt.Printf("\n🩺 Found %d error(s) and %d warning(s).\n", errors, warnings)
+	if errors > 0 {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	cacheDirEnv       = "TEMPLUI_CACHE_DIR" // Overrides the cache location
+	cacheIndexName    = "index.json"
+	cacheObjectsDir   = "objects"
+	cacheIndexVersion = 1
+)
+
+// errNotCached is returned in offline mode when a file isn't in the cache.
+var errNotCached = errors.New("not in cache")
+
+// semverTagRegex matches release tags like v1.2.3 or v1.2.3-rc.1, which are never moved.
+var semverTagRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// isImmutableRef reports whether the content of a ref never changes, so cached files never need re-fetching.
+func isImmutableRef(ref string) bool {
+	return commitSHARegex.MatchString(ref) || semverTagRegex.MatchString(ref)
+}
+
+// cacheIndex maps fetched locations to content-addressed objects in the cache directory.
+type cacheIndex struct {
+	Version int                   `json:"version"`
+	Files   map[string]cacheEntry `json:"files"`   // Keyed by the location the file was fetched from
+	Commits map[string]string     `json:"commits"` // Resolved commits, keyed by the location of the ref root
+}
+
+// cacheEntry describes a cached file.
+type cacheEntry struct {
+	Location  string    `json:"location"`
+	Ref       string    `json:"ref"`
+	Path      string    `json:"path"` // Path relative to the repository root
+	SHA256    string    `json:"sha256"`
+	Size      int       `json:"size"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// fileCache is an on-disk, content-addressed store for registry and component files.
+type fileCache struct {
+	dir   string
+	mu    sync.Mutex
+	index *cacheIndex
+}
+
+// cacheDir returns the directory of the templUI cache.
+func cacheDir() (string, error) {
+	if dir := os.Getenv(cacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine cache directory (set %s): %w", cacheDirEnv, err)
+	}
+	return filepath.Join(userCacheDir, "templui"), nil
+}
+
+// openFileCache opens the cache in the user cache directory. The directory is created on first write.
+func openFileCache() (*fileCache, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	cache := &fileCache{dir: dir}
+	err = cache.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// loadIndex reads the cache index, starting with an empty one if it doesn't exist or is unreadable.
+func (c *fileCache) loadIndex() error {
+	c.index = &cacheIndex{
+		Version: cacheIndexVersion,
+		Files:   make(map[string]cacheEntry),
+		Commits: make(map[string]string),
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, cacheIndexName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading cache index: %w", err)
+	}
+	var index cacheIndex
+	if json.Unmarshal(data, &index) != nil || index.Version != cacheIndexVersion {
+		// A corrupt or incompatible index only costs a re-download.
+		return nil
+	}
+	if index.Files != nil {
+		c.index.Files = index.Files
+	}
+	if index.Commits != nil {
+		c.index.Commits = index.Commits
+	}
+	return nil
+}
+
+// saveIndex writes the cache index. The caller must hold c.mu.
+func (c *fileCache) saveIndex() error {
+	data, err := json.MarshalIndent(c.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error creating cache index data: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(c.dir, cacheIndexName), data)
+}
+
+// objectPath returns where the content with the given hash is stored.
+func (c *fileCache) objectPath(sha string) string {
+	return filepath.Join(c.dir, cacheObjectsDir, sha[:2], sha)
+}
+
+// get returns the cached content fetched from location, verifying it against its hash.
+func (c *fileCache) get(location string) ([]byte, cacheEntry, bool) {
+	c.mu.Lock()
+	entry, ok := c.index.Files[location]
+	c.mu.Unlock()
+	if !ok {
+		return nil, cacheEntry{}, false
+	}
+	data, err := os.ReadFile(c.objectPath(entry.SHA256))
+	if err != nil || hashContent(data) != entry.SHA256 {
+		return nil, cacheEntry{}, false
+	}
+	return data, entry, true
+}
+
+// put stores content fetched from location.
+func (c *fileCache) put(location, ref, repoPath string, data []byte) error {
+	sha := hashContent(data)
+	objectPath := c.objectPath(sha)
+	if _, err := os.Stat(objectPath); err != nil {
+		err = writeFileAtomic(objectPath, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index.Files[location] = cacheEntry{
+		Location:  location,
+		Ref:       ref,
+		Path:      repoPath,
+		SHA256:    sha,
+		Size:      len(data),
+		FetchedAt: time.Now().UTC(),
+	}
+	return c.saveIndex()
+}
+
+// commit returns a cached commit for the ref rooted at location.
+func (c *fileCache) commit(location string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	commit, ok := c.index.Commits[location]
+	return commit, ok
+}
+
+// putCommit stores the commit a ref rooted at location resolved to.
+func (c *fileCache) putCommit(location, commit string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index.Commits[location] = commit
+	return c.saveIndex()
+}
+
+// entries returns all cached files.
+func (c *fileCache) entries() []cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]cacheEntry, 0, len(c.index.Files))
+	for _, entry := range c.index.Files {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// prune removes the cached files of the given refs, or everything if no refs are given,
+// and deletes objects no longer referenced. It returns the number of removed files.
+func (c *fileCache) prune(refs []string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	pruneRef := make(map[string]bool)
+	for _, ref := range refs {
+		pruneRef[ref] = true
+	}
+	for location, entry := range c.index.Files {
+		if len(refs) == 0 || pruneRef[entry.Ref] {
+			delete(c.index.Files, location)
+			removed++
+		}
+	}
+	if len(refs) == 0 {
+		c.index.Commits = make(map[string]string)
+	}
+
+	// Delete objects that no entry points to anymore.
+	referenced := make(map[string]bool)
+	for _, entry := range c.index.Files {
+		referenced[entry.SHA256] = true
+	}
+	objectsDir := filepath.Join(c.dir, cacheObjectsDir)
+	err := filepath.WalkDir(objectsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() && !referenced[d.Name()] {
+			return os.Remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to clean cache objects: %w", err)
+	}
+
+	if _, err := os.Stat(c.dir); os.IsNotExist(err) {
+		return removed, nil
+	}
+	return removed, c.saveIndex()
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so concurrent readers never see partially written files.
+func writeFileAtomic(path string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// cachedSource serves files of a remote registry source from the on-disk cache.
+// Files of immutable refs are only fetched once; mutable refs (branches) are re-fetched,
+// falling back to the cache when the source is unreachable. In offline mode only the cache is used.
+type cachedSource struct {
+	inner   registrySource
+	cache   *fileCache
+	offline bool
+}
+
+func (s *cachedSource) location(ref, repoPath string) string {
+	return s.inner.location(ref, repoPath)
+}
+
+func (s *cachedSource) fetch(ref, repoPath string) ([]byte, error) {
+	location := s.inner.location(ref, repoPath)
+	cached, entry, ok := s.cache.get(location)
+	if ok && (s.offline || isImmutableRef(ref)) {
+		return cached, nil
+	}
+	if s.offline {
+		return nil, fmt.Errorf("%s is %w (offline mode). Run 'templui cache prefetch@%s' while online", location, errNotCached, ref)
+	}
+
+	data, err := s.inner.fetch(ref, repoPath)
+	if err != nil {
+		if ok && !errors.Is(err, errNotFound) {
+			fmt.Printf("   Warning: %v. Using cached copy from %s.\n", err, entry.FetchedAt.Local().Format(time.DateTime))
+			return cached, nil
+		}
+		return nil, err
+	}
+	err = s.cache.put(location, ref, repoPath, data)
+	if err != nil {
+		fmt.Printf("   Warning: Could not cache %s: %v\n", location, err)
+	}
+	return data, nil
+}
+
+func (s *cachedSource) commit(ref string) (string, error) {
+	if commitSHARegex.MatchString(ref) {
+		return ref, nil
+	}
+	location := s.inner.location(ref, "")
+	cached, ok := s.cache.commit(location)
+	if ok && (s.offline || isImmutableRef(ref)) {
+		return cached, nil
+	}
+	if s.offline {
+		return "", fmt.Errorf("commit of ref '%s' is %w (offline mode)", ref, errNotCached)
+	}
+
+	commit, err := s.inner.commit(ref)
+	if err != nil {
+		if ok {
+			return cached, nil
+		}
+		return "", err
+	}
+	if commit != "" {
+		err = s.cache.putCommit(location, commit)
+		if err != nil {
+			fmt.Printf("   Warning: Could not cache commit of ref '%s': %v\n", ref, err)
+		}
+	}
+	return commit, nil
+}
+
+func (s *cachedSource) latestRelease() (string, error) {
+	if s.offline {
+		return "", fmt.Errorf("latest release is %w (offline mode)", errNotCached)
+	}
+	return s.inner.latestRelease()
+}
+
+// withCache wraps remote registry sources with the on-disk cache. Local directories are used as-is.
+func withCache(src registrySource, offline bool) (registrySource, error) {
+	if _, ok := src.(dirSource); ok {
+		return src, nil
+	}
+	cache, err := openFileCache()
+	if err != nil {
+		if offline {
+			return nil, err
+		}
+		fmt.Printf("Warning: Cache disabled: %v\n", err)
+		return src, nil
+	}
+	return &cachedSource{inner: src, cache: cache, offline: offline}, nil
+}
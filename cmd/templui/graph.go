@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Formats of the 'graph' command.
+const (
+	graphDOT     = "dot"
+	graphMermaid = "mermaid"
+)
+
+// runGraph handles the 'graph' command logic. It prints the dependency graph of the registry,
+// or of the installed components, limited to the given components and their dependencies.
+func runGraph(args []string, ref string, format string, installed bool) int {
+	if format != graphDOT && format != graphMermaid {
+		failf("Error: Unsupported graph format '%s'. Use '%s' or '%s'.\n", format, graphDOT, graphMermaid)
+		return exitUsage
+	}
+	if installed && ref != "" {
+		failf("Error: --installed can't be combined with a ref.\n")
+		return exitUsage
+	}
+
+	var names []string
+	deps := make(map[string][]string)
+	if installed {
+		config, err := loadConfig()
+		if err != nil {
+			failf("Error loading config: %v\n", err)
+			return exitError
+		}
+		lock, err := loadLockfile()
+		if err != nil {
+			failf("Error loading lockfile: %v\n", err)
+			return exitError
+		}
+		names, err = getInstalledComponentNames(config, lock)
+		if err != nil {
+			failf("Error detecting installed components: %v\n", err)
+			return exitError
+		}
+		for name, d := range installedDependencies(names, lock) {
+			deps[name] = d.dependencies
+		}
+	} else {
+		if ref == "" {
+			ref = getDefaultRef()
+		}
+		result.Ref = ref
+		registry, err := fetchRegistry(ref)
+		if err != nil {
+			failf("Error fetching registry for ref '%s': %v\n", ref, err)
+			return exitError
+		}
+		componentMap := make(map[string]ComponentDef)
+		for _, comp := range registry.Components {
+			names = append(names, comp.Name)
+			deps[comp.Name] = comp.Dependencies
+			componentMap[comp.Name] = comp
+		}
+		_, _, err = resolveComponents(names, componentMap, ref)
+		if errors.Is(err, errDependencyCycle) {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	if len(args) > 0 {
+		for _, name := range args {
+			if !slices.Contains(names, name) {
+				failf("❌ Component '%s' not found.\n", name)
+				return exitError
+			}
+		}
+		names = reachableComponents(args, deps)
+	}
+
+	graph := renderGraph(names, deps, format)
+	result.Graph = graph
+	fmt.Print(graph)
+	return exitOK
+}
+
+// reachableComponents returns the given components and everything they depend on, sorted.
+func reachableComponents(roots []string, deps map[string][]string) []string {
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		for _, dep := range deps[name] {
+			visit(dep)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// renderGraph renders the components and their dependencies as a Graphviz DOT or Mermaid graph.
+// Components without dependencies that nothing depends on are shown as single nodes.
+func renderGraph(names []string, deps map[string][]string, format string) string {
+	var b strings.Builder
+	if format == graphMermaid {
+		b.WriteString("graph TD\n")
+	} else {
+		b.WriteString("digraph templui {\n")
+	}
+
+	hasEdges := make(map[string]bool)
+	for _, name := range names {
+		for _, dep := range deps[name] {
+			hasEdges[name], hasEdges[dep] = true, true
+		}
+	}
+	for _, name := range names {
+		if !hasEdges[name] {
+			if format == graphMermaid {
+				fmt.Fprintf(&b, "  %s\n", name)
+			} else {
+				fmt.Fprintf(&b, "  %q;\n", name)
+			}
+			continue
+		}
+		for _, dep := range deps[name] {
+			if format == graphMermaid {
+				fmt.Fprintf(&b, "  %s --> %s\n", name, dep)
+			} else {
+				fmt.Fprintf(&b, "  %q -> %q;\n", name, dep)
+			}
+		}
+	}
+
+	if format != graphMermaid {
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"regexp"
@@ -39,6 +40,17 @@ func readFileVersion(filePath string) (string, error) {
 	return "", nil // No version comment found.
 }
 
+// askYesNo asks the user a yes/no question, defaulting to no.
+func askYesNo(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && input == "" {
+		fmt.Println()
+		return false
+	}
+	return strings.ToLower(strings.TrimSpace(input)) == "y"
+}
+
 // askForOverwrite prompts the user to confirm overwriting an existing file.
 func askForOverwrite(filePath, oldRef, newRef string) bool {
 	reader := bufio.NewReader(os.Stdin)
@@ -62,57 +74,49 @@ func askForOverwrite(filePath, oldRef, newRef string) bool {
 }
 
 // replaceImports replaces internal templUI import paths with the user's configured module name and paths.
-func replaceImports(data []byte, config Config, context string) []byte {
-	content := string(data)
-	// Pattern to find "github.com/templui/templui/internal/..." imports.
-	// It captures the part after "internal/", e.g., "components/icon" or "utils".
-	internalImportPattern := `"github.com/templui/templui/internal/([^"]+)"`
-	re := regexp.MustCompile(internalImportPattern)
-
-	modified := false // Flag to track if any replacement occurred
-
-	newContent := re.ReplaceAllStringFunc(content, func(originalFullImport string) string {
-		// originalFullImport is like: "github.com/templui/templui/internal/components/icon" (with quotes)
-		// submatches[0] is originalFullImport
-		// submatches[1] is the captured group, e.g., "components/icon" or "utils"
-		submatches := re.FindStringSubmatch(originalFullImport)
-		if len(submatches) < 2 {
-			return originalFullImport // Should not happen if regex matches
-		}
-		repoRelativePath := submatches[1] // This is "components/icon" or "utils"
-
-		var newImportPath string
-		if strings.HasPrefix(repoRelativePath, "components/") {
-			// For "components/icon", new path is "config.ModuleName/config.ComponentsDir/icon"
-			componentName := strings.TrimPrefix(repoRelativePath, "components/")
-			newImportPath = fmt.Sprintf("%s/%s/%s", config.ModuleName, config.ComponentsDir, componentName)
-			modified = true
-		} else if repoRelativePath == "utils" || strings.HasPrefix(repoRelativePath, "utils/") {
-			// For "utils", new path is "config.ModuleName/config.UtilsDir"
-			// For "utils/sub", new path is "config.ModuleName/config.UtilsDir/sub"
-			utilSubPath := strings.TrimPrefix(repoRelativePath, "utils") // "" or "/sub"
-			utilSubPath = strings.TrimPrefix(utilSubPath, "/")           // "sub" or ""
-
-			if utilSubPath == "" {
-				newImportPath = fmt.Sprintf("%s/%s", config.ModuleName, config.UtilsDir)
-			} else {
-				newImportPath = fmt.Sprintf("%s/%s/%s", config.ModuleName, config.UtilsDir, utilSubPath)
-			}
-			modified = true
-		} else {
-			// Path doesn't match known structures, return original.
-			return originalFullImport
-		}
-		return fmt.Sprintf(`"%s"`, newImportPath)
-	})
+// scope is the named registry the file comes from ("" for the default registry).
+func replaceImports(data []byte, config Config, scope, context string) ([]byte, error) {
+	newContent, modified, err := rewriteImports(data, config, scope)
+	if err != nil {
+		return nil, err
+	}
+	if modified {
+		logImportAdjustment(context)
+	}
+	return newContent, nil
+}
 
-	if modified { // Use the flag to log
-		logPrefix := "    ->"
-		if context != "" {
-			logPrefix = fmt.Sprintf("    -> [%s]", context)
-		}
-		fmt.Printf("%s Adjusted import paths according to .templui.json config.\n", logPrefix)
+// logImportAdjustment reports that import paths of a file were adjusted.
+func logImportAdjustment(context string) {
+	logPrefix := "    ->"
+	if context != "" {
+		logPrefix = fmt.Sprintf("    -> [%s]", context)
+	}
+	fmt.Printf("%s Adjusted import paths according to .templui.json config.\n", logPrefix)
+}
+
+// alignVersionComment reuses the version comment of a local file for the pristine content when
+// both refer to the same ref, so files installed by another CLI version don't show up as modified.
+func alignVersionComment(pristine, local []byte) []byte {
+	pristineLine, pristineRest, _ := bytes.Cut(pristine, []byte("\n"))
+	localLine, _, _ := bytes.Cut(local, []byte("\n"))
+	pristineMatch := versionRegex.FindSubmatch(pristineLine)
+	localMatch := versionRegex.FindSubmatch(localLine)
+	if pristineMatch == nil || localMatch == nil || !bytes.Equal(pristineMatch[1], localMatch[1]) {
+		return pristine
 	}
+	aligned := append([]byte{}, localLine...)
+	aligned = append(aligned, '\n')
+	return append(aligned, pristineRest...)
+}
 
-	return []byte(newContent)
+// stripHeader removes the version and documentation comments added at installation.
+func stripHeader(data []byte) []byte {
+	for {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if !found || (!versionRegex.Match(line) && !bytes.HasPrefix(line, []byte("// 📚 Documentation:"))) {
+			return data
+		}
+		data = rest
+	}
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout = 30 * time.Second       // Per request, including reading the body
+	httpRetries        = 3                      // Retries after the first attempt
+	httpInitialBackoff = 500 * time.Millisecond // Doubled with each retry
+	httpMaxRetryWait   = time.Minute            // Longer Retry-After or rate limit resets fail instead of waiting
+)
+
+// githubHosts are the hosts GITHUB_TOKEN is sent to.
+var githubHosts = map[string]bool{
+	"github.com":                true,
+	"api.github.com":            true,
+	"raw.githubusercontent.com": true,
+}
+
+// httpClient sends all HTTP requests of the CLI (replaced in main to apply --timeout).
+var httpClient = newHTTPClient(defaultHTTPTimeout)
+
+// retryClient is an HTTP client with a timeout that retries failed requests with exponential backoff.
+// Proxies are taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+type retryClient struct {
+	client  *http.Client
+	retries int
+	backoff time.Duration
+	maxWait time.Duration
+}
+
+// newHTTPClient creates a retryClient with the given timeout per request.
+func newHTTPClient(timeout time.Duration) *retryClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return &retryClient{
+		client:  &http.Client{Timeout: timeout, Transport: transport},
+		retries: httpRetries,
+		backoff: httpInitialBackoff,
+		maxWait: httpMaxRetryWait,
+	}
+}
+
+// get sends a GET request with the given headers and returns the response with its body read.
+// Network errors, 429 and 5xx responses, and GitHub rate limits are retried, honoring Retry-After.
+// Requests to GitHub are authenticated with GITHUB_TOKEN, unless the headers set Authorization.
+func (c *retryClient) get(rawURL string, headers map[string]string) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		resp, body, err := c.do(rawURL, headers)
+		wait, retry := c.retryDelay(attempt, resp, err)
+		if !retry {
+			return resp, body, err
+		}
+		var reason string
+		var urlErr *url.Error
+		switch {
+		case errors.As(err, &urlErr):
+			reason = urlErr.Err.Error() // Without the URL, which is already printed.
+		case err != nil:
+			reason = err.Error()
+		default:
+			reason = fmt.Sprintf("status code %d", resp.StatusCode)
+		}
+		fmt.Printf("⏳ %s: %s, retrying in %s (%d/%d)\n", rawURL, reason, wait.Round(100*time.Millisecond), attempt+1, c.retries)
+		time.Sleep(wait)
+	}
+}
+
+// do sends a single GET request and reads the response body.
+func (c *retryClient) do(rawURL string, headers map[string]string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "templui/"+version)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && req.Header.Get("Authorization") == "" && githubHosts[req.URL.Hostname()] {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body from %s: %w", rawURL, err)
+	}
+	return resp, body, nil
+}
+
+// retryDelay returns how long to wait before retrying a request, or false if it shouldn't be retried.
+func (c *retryClient) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= c.retries {
+		return 0, false
+	}
+	backoff := c.backoff << attempt
+	backoff += rand.N(backoff/2 + 1) // Jitter, so concurrent downloads don't retry in lockstep.
+
+	if err != nil {
+		// Network errors (timeouts, refused or reset connections, DNS) are retried, others
+		// like invalid URLs or certificate errors are not. *url.Error is a net.Error itself,
+		// so the error it wraps is checked.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return backoff, true
+		}
+		return 0, false
+	}
+
+	rateLimited := resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0")
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 && !rateLimited {
+		return 0, false
+	}
+	if resp.StatusCode == http.StatusNotImplemented {
+		return 0, false
+	}
+	wait, ok := serverRetryWait(resp)
+	if !ok {
+		return backoff, true
+	}
+	if wait > c.maxWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+// serverRetryWait returns how long the server asks to wait, from Retry-After (seconds or an HTTP date)
+// or, for an exhausted GitHub rate limit, X-RateLimit-Reset.
+func serverRetryWait(resp *http.Response) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(max(seconds, 0)) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return max(time.Until(date), 0), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(reset, 0)), 0), true
+		}
+	}
+	return 0, false
+}
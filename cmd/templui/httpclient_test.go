@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHTTPClient returns a retryClient with short backoffs, so retries don't slow down the tests.
+func newTestHTTPClient() *retryClient {
+	return &retryClient{client: &http.Client{Timeout: 5 * time.Second}, retries: 2, backoff: time.Millisecond, maxWait: time.Minute}
+}
+
+// newStatusServer answers the requests in turn with the given responses, repeating the last one.
+func newStatusServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		responses[min(n, len(responses))-1](w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// status answers with a status code and the given headers.
+func status(code int, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(http.StatusText(code)))
+	}
+}
+
+func TestRetryClientGet(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []func(w http.ResponseWriter)
+		wantStatus   int
+		wantRequests int32
+	}{
+		{
+			name:         "429 with Retry-After then success",
+			responses:    []func(w http.ResponseWriter){status(http.StatusTooManyRequests, "Retry-After", "0"), status(http.StatusOK)},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "Retry-After longer than the maximum wait",
+			responses:    []func(w http.ResponseWriter){status(http.StatusTooManyRequests, "Retry-After", "3600"), status(http.StatusOK)},
+			wantStatus:   http.StatusTooManyRequests,
+			wantRequests: 1,
+		},
+		{
+			name:         "5xx then success",
+			responses:    []func(w http.ResponseWriter){status(http.StatusBadGateway), status(http.StatusServiceUnavailable), status(http.StatusOK)},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after the maximum attempts",
+			responses:    []func(w http.ResponseWriter){status(http.StatusInternalServerError)},
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 3,
+		},
+		{
+			name:         "GitHub rate limit",
+			responses:    []func(w http.ResponseWriter){status(http.StatusForbidden, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", "0"), status(http.StatusOK)},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "client errors are not retried",
+			responses:    []func(w http.ResponseWriter){status(http.StatusNotFound), status(http.StatusOK)},
+			wantStatus:   http.StatusNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:         "501 is not retried",
+			responses:    []func(w http.ResponseWriter){status(http.StatusNotImplemented), status(http.StatusOK)},
+			wantStatus:   http.StatusNotImplemented,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newStatusServer(t, tt.responses...)
+			resp, body, err := newTestHTTPClient().get(server.URL, nil)
+			if err != nil {
+				t.Fatalf("get() error: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || string(body) != http.StatusText(tt.wantStatus) {
+				t.Errorf("get() = %d %q, want %d", resp.StatusCode, body, tt.wantStatus)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryClientHonorsRetryAfter(t *testing.T) {
+	server, _ := newStatusServer(t, status(http.StatusTooManyRequests, "Retry-After", "0"), status(http.StatusOK))
+	client := newTestHTTPClient()
+	client.backoff = 10 * time.Second // Only Retry-After keeps the test fast.
+
+	start := time.Now()
+	resp, _, err := client.get(server.URL, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("get() = %v, %v, want 200", resp, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("get() waited %s, want the 0s of Retry-After", elapsed)
+	}
+}
+
+func TestRetryClientNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close() // Connections are refused from now on.
+
+	_, _, err := newTestHTTPClient().get(url, nil)
+	if err == nil {
+		t.Fatal("get() of a closed server succeeded")
+	}
+}
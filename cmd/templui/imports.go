@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// internalImportPrefix is the import path prefix of the internal packages of the templUI repository.
+const internalImportPrefix = "github.com/templui/templui/internal/"
+
+// sourceEdit replaces the bytes between two offsets of a file.
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// parseFileHeader parses the package clause and the imports of a .go or .templ file.
+// templ files start like Go files, and parsing stops after the imports, before any templ syntax.
+func parseFileHeader(data []byte) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", data, parser.ImportsOnly)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fset, file, nil
+}
+
+// mapImports passes the alias ("" if none) and path of every import spec of a file to mapImport
+// and replaces them with the returned alias and path. Comments and string literals elsewhere are left untouched.
+// The returned flag reports whether mapImport matched any import.
+func mapImports(data []byte, mapImport func(alias, importPath string) (string, string, bool)) ([]byte, bool, error) {
+	fset, file, err := parseFileHeader(data)
+	if err != nil {
+		return data, false, fmt.Errorf("cannot parse imports: %w", err)
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	matched := false
+	var edits []sourceEdit
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		alias := ""
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		newAlias, newPath, ok := mapImport(alias, importPath)
+		if !ok {
+			continue
+		}
+		matched = true
+
+		switch {
+		case newAlias == alias:
+		case alias == "":
+			edits = append(edits, sourceEdit{start: offset(spec.Path.Pos()), end: offset(spec.Path.Pos()), text: newAlias + " "})
+		case newAlias == "":
+			edits = append(edits, sourceEdit{start: offset(spec.Name.Pos()), end: offset(spec.Path.Pos())})
+		default:
+			edits = append(edits, sourceEdit{start: offset(spec.Name.Pos()), end: offset(spec.Name.End()), text: newAlias})
+		}
+		if newPath != importPath {
+			edits = append(edits, sourceEdit{start: offset(spec.Path.Pos()), end: offset(spec.Path.End()), text: strconv.Quote(newPath)})
+		}
+	}
+	return applyEdits(data, edits), matched, nil
+}
+
+// renamePackage changes the package clause of a file from one name to another.
+// Files declaring another package are returned unchanged.
+func renamePackage(data []byte, from, to string) ([]byte, error) {
+	if from == to {
+		return data, nil
+	}
+	fset, file, err := parseFileHeader(data)
+	if err != nil {
+		return data, fmt.Errorf("cannot parse package clause: %w", err)
+	}
+	if file.Name.Name != from {
+		return data, nil
+	}
+	return applyEdits(data, []sourceEdit{{
+		start: fset.Position(file.Name.Pos()).Offset,
+		end:   fset.Position(file.Name.End()).Offset,
+		text:  to,
+	}}), nil
+}
+
+// applyEdits applies non-overlapping edits to data.
+func applyEdits(data []byte, edits []sourceEdit) []byte {
+	if len(edits) == 0 {
+		return data
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out []byte
+	last := 0
+	for _, edit := range edits {
+		out = append(out, data[last:edit.start]...)
+		out = append(out, edit.text...)
+		last = edit.end
+	}
+	return append(out, data[last:]...)
+}
+
+// rewriteImports rewrites internal templUI import paths without logging. Imports of packages
+// renamed by the config get the original package name as alias, so the code using them still compiles.
+// Files of a named registry (scope, "" for the default registry) also have the internal import paths of
+// their registry's repository rewritten, to the components and utils installed from it.
+// The returned flag reports whether any import path was adjusted.
+func rewriteImports(data []byte, config Config, scope string) ([]byte, bool, error) {
+	return mapImports(data, func(alias, importPath string) (string, string, bool) {
+		// The part after "internal/", e.g., "components/icon" or "utils".
+		importScope := scope
+		repoRelativePath, ok := strings.CutPrefix(importPath, registryImportPrefix(scope))
+		if !ok {
+			importScope = ""
+			repoRelativePath, ok = strings.CutPrefix(importPath, internalImportPrefix)
+		}
+		if !ok {
+			return alias, importPath, false
+		}
+
+		if componentPath, ok := strings.CutPrefix(repoRelativePath, "components/"); ok {
+			// For "components/icon", new path is the import path of the icon component,
+			// by default "config.ModuleName/config.ComponentsDir/icon".
+			componentName, subPath, _ := strings.Cut(componentPath, "/")
+			componentName = qualifiedName(importScope, componentName)
+			newPath := config.componentImportPath(componentName)
+			if subPath != "" {
+				return alias, newPath + "/" + subPath, true
+			}
+			if upstream := upstreamPackageName(componentName); alias == "" && config.componentPackage(componentName) != upstream {
+				alias = upstream
+			}
+			return alias, newPath, true
+		}
+		if repoRelativePath == "utils" || strings.HasPrefix(repoRelativePath, "utils/") {
+			// For "utils", new path is "config.ModuleName/config.UtilsDir"
+			// For "utils/sub", new path is "config.ModuleName/config.UtilsDir/sub"
+			utilsPath := utilsImportPath(config, importScope)
+			if alias == "" && repoRelativePath == "utils" && path.Base(utilsPath) != "utils" {
+				alias = "utils"
+			}
+			return alias, utilsPath + strings.TrimPrefix(repoRelativePath, "utils"), true
+		}
+		// Path doesn't match known structures, keep the original.
+		return alias, importPath, false
+	})
+}
+
+// utilsImportPath returns the import path of the installed utils of a registry ("" for the default registry).
+func utilsImportPath(config Config, scope string) string {
+	return config.ModuleName + "/" + filepath.ToSlash(config.utilsDir(scope))
+}
+
+// restoreImports reverses rewriteImports, mapping import paths of the installed components
+// and utils back to the internal paths of the repositories of their registries.
+// Files that can't be parsed are returned unchanged, so they show up as modified.
+func restoreImports(data []byte, config Config) []byte {
+	restored, _, _ := mapImports(data, func(alias, importPath string) (string, string, bool) {
+		var newPath string
+		componentName, subPath, isComponent := installedComponentImport(config, importPath)
+		utilsScope, utilsSubPath, isUtils := installedUtilsImport(config, importPath)
+		switch {
+		case isComponent:
+			scope, componentName := splitScope(componentName)
+			newPath = registryImportPrefix(scope) + "components/" + componentName + subPath
+		case isUtils:
+			newPath = registryImportPrefix(utilsScope) + "utils" + utilsSubPath
+		default:
+			return alias, importPath, false
+		}
+		// Drop aliases added by rewriteImports.
+		if alias == path.Base(newPath) || alias == upstreamPackageName(path.Base(newPath)) {
+			alias = ""
+		}
+		return alias, newPath, true
+	})
+	return restored
+}
+
+// installedUtilsImport returns the named registry ("" for the default registry) whose installed utils
+// an import path of the project refers to, and the path of the imported package within them ("" or "/sub").
+func installedUtilsImport(config Config, importPath string) (string, string, bool) {
+	// The utils of named registries are installed inside the default utils directory, so they are checked first.
+	for scope := range namedRegistries {
+		utilsPath := utilsImportPath(config, scope)
+		if importPath == utilsPath || strings.HasPrefix(importPath, utilsPath+"/") {
+			return scope, strings.TrimPrefix(importPath, utilsPath), true
+		}
+	}
+	utilsPath := utilsImportPath(config, "")
+	if importPath == utilsPath || strings.HasPrefix(importPath, utilsPath+"/") {
+		return "", strings.TrimPrefix(importPath, utilsPath), true
+	}
+	return "", "", false
+}
+
+// installedComponentImport returns the name of the component an import path of the project refers to,
+// and the path of the imported package within the component ("" or "/sub").
+func installedComponentImport(config Config, importPath string) (string, string, bool) {
+	// Mapped components take precedence, the most specific directory first.
+	match := ""
+	for name := range config.Components {
+		componentPath := config.componentImportPath(name)
+		if (importPath == componentPath || strings.HasPrefix(importPath, componentPath+"/")) &&
+			(match == "" || len(componentPath) > len(config.componentImportPath(match))) {
+			match = name
+		}
+	}
+	if match != "" {
+		return match, strings.TrimPrefix(importPath, config.componentImportPath(match)), true
+	}
+
+	componentPath, ok := strings.CutPrefix(importPath, config.ModuleName+"/"+config.ComponentsDir+"/")
+	if !ok {
+		return "", "", false
+	}
+	// Components of named registries are installed in a directory named after the registry.
+	if dir, rest, ok := strings.Cut(componentPath, "/"); ok && namedRegistries["@"+dir].URL != "" {
+		componentPath = qualifiedName("@"+dir, rest)
+	}
+	scope, componentPath := splitScope(componentPath)
+	name, subPath, _ := strings.Cut(componentPath, "/")
+	if subPath != "" {
+		subPath = "/" + subPath
+	}
+	return qualifiedName(scope, name), subPath, true
+}
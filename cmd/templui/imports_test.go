@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const upstream = "github.com/templui/templui/internal/"
+
+var testConfig = Config{
+	ComponentsDir: "components",
+	UtilsDir:      "utils",
+	ModuleName:    "example.com/app",
+}
+
+func TestRewriteImports(t *testing.T) {
+	mapped := testConfig
+	mapped.UtilsDir = "pkg/helpers"
+	mapped.Components = map[string]ComponentMapping{
+		"icon": {Dir: "ui/uiicon", Package: "uiicon"},
+	}
+
+	tests := []struct {
+		name         string
+		config       Config
+		in           string
+		want         string
+		wantAdjusted bool
+	}{
+		{
+			name:         "component import",
+			config:       testConfig,
+			in:           "package card\n\nimport \"" + upstream + "components/icon\"\n",
+			want:         "package card\n\nimport \"example.com/app/components/icon\"\n",
+			wantAdjusted: true,
+		},
+		{
+			name:         "aliased import keeps its alias",
+			config:       testConfig,
+			in:           "package card\n\nimport (\n\tic \"" + upstream + "components/icon\"\n\t. \"" + upstream + "utils\"\n)\n",
+			want:         "package card\n\nimport (\n\tic \"example.com/app/components/icon\"\n\t. \"example.com/app/utils\"\n)\n",
+			wantAdjusted: true,
+		},
+		{
+			name:   "import paths in comments and string literals are kept",
+			config: testConfig,
+			in: "package card\n\n// Uses " + upstream + "components/icon.\nimport \"fmt\"\n\n" +
+				"const path = \"" + upstream + "components/icon\"\n\n/* \"" + upstream + "utils\" */\n",
+			want: "package card\n\n// Uses " + upstream + "components/icon.\nimport \"fmt\"\n\n" +
+				"const path = \"" + upstream + "components/icon\"\n\n/* \"" + upstream + "utils\" */\n",
+		},
+		{
+			name:         "nested utils package",
+			config:       testConfig,
+			in:           "package card\n\nimport \"" + upstream + "utils/sub/pkg\"\n",
+			want:         "package card\n\nimport \"example.com/app/utils/sub/pkg\"\n",
+			wantAdjusted: true,
+		},
+		{
+			name:         "utils dir not named utils gets an alias",
+			config:       mapped,
+			in:           "package card\n\nimport (\n\t\"" + upstream + "utils\"\n\t\"" + upstream + "utils/sub\"\n)\n",
+			want:         "package card\n\nimport (\n\tutils \"example.com/app/pkg/helpers\"\n\t\"example.com/app/pkg/helpers/sub\"\n)\n",
+			wantAdjusted: true,
+		},
+		{
+			name:         "mapped component package gets the upstream name as alias",
+			config:       mapped,
+			in:           "package card\n\nimport \"" + upstream + "components/icon\"\n",
+			want:         "package card\n\nimport icon \"example.com/app/ui/uiicon\"\n",
+			wantAdjusted: true,
+		},
+		{
+			name:   "templ file",
+			config: testConfig,
+			in: "package card\n\nimport \"" + upstream + "utils\"\n\n" +
+				"templ Card() {\n\t<div class={ utils.TwMerge(\"" + upstream + "utils\") }></div>\n}\n",
+			want: "package card\n\nimport \"example.com/app/utils\"\n\n" +
+				"templ Card() {\n\t<div class={ utils.TwMerge(\"" + upstream + "utils\") }></div>\n}\n",
+			wantAdjusted: true,
+		},
+		{
+			name:   "other imports",
+			config: testConfig,
+			in:     "package card\n\nimport (\n\t\"fmt\"\n\t\"github.com/a-h/templ\"\n)\n",
+			want:   "package card\n\nimport (\n\t\"fmt\"\n\t\"github.com/a-h/templ\"\n)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, adjusted, err := rewriteImports([]byte(tt.in), tt.config, "")
+			if err != nil {
+				t.Fatalf("rewriteImports() error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("rewriteImports() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if adjusted != tt.wantAdjusted {
+				t.Errorf("rewriteImports() adjusted = %v, want %v", adjusted, tt.wantAdjusted)
+			}
+
+			restored := restoreImports(got, tt.config)
+			if string(restored) != tt.in {
+				t.Errorf("restoreImports() =\n%s\nwant\n%s", restored, tt.in)
+			}
+		})
+	}
+}
+
+func TestRewriteImportsParseError(t *testing.T) {
+	in := "package card\n\nimport (\n\t\"" + upstream + "utils\"\n"
+	got, _, err := rewriteImports([]byte(in), testConfig, "")
+	if err == nil {
+		t.Fatalf("rewriteImports() = %q, want a parse error", got)
+	}
+	if !strings.Contains(err.Error(), "4:") {
+		t.Errorf("rewriteImports() error %q doesn't point at line 4", err)
+	}
+}
+
+func TestRenamePackage(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		from, to string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "renames package clause",
+			in:   "// Package utils has helpers.\npackage utils\n\nimport \"fmt\"\n",
+			from: "utils",
+			to:   "helpers",
+			want: "// Package utils has helpers.\npackage helpers\n\nimport \"fmt\"\n",
+		},
+		{
+			name: "templ file",
+			in:   "package icon\n\ntempl Icon() {\n\t<span>package icon</span>\n}\n",
+			from: "icon",
+			to:   "uiicon",
+			want: "package uiicon\n\ntempl Icon() {\n\t<span>package icon</span>\n}\n",
+		},
+		{
+			name: "other package is kept",
+			in:   "package card\n",
+			from: "utils",
+			to:   "helpers",
+			want: "package card\n",
+		},
+		{
+			name: "same name",
+			in:   "package utils\n",
+			from: "utils",
+			to:   "utils",
+			want: "package utils\n",
+		},
+		{
+			name:    "parse error",
+			in:      "packge utils\n",
+			from:    "utils",
+			to:      "helpers",
+			want:    "packge utils\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renamePackage([]byte(tt.in), tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renamePackage() error = %v, want error %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("renamePackage() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
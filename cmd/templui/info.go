@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// componentDocsBaseURL is the base URL of the component documentation, followed by the component slug.
+const componentDocsBaseURL = "https://templui.io/docs/components/"
+
+// componentInfo is the full metadata of a component as shown by 'info'.
+type componentInfo struct {
+	ComponentDef
+	DocsURL        string              `json:"docsUrl"`
+	DependencyTree []dependencyNode    `json:"dependencyTree,omitempty"` // Transitive dependencies
+	Installed      *installedComponent `json:"installed,omitempty"`      // nil if not installed locally
+}
+
+// dependencyNode is a component in a dependency tree.
+type dependencyNode struct {
+	Name         string           `json:"name"`
+	Missing      bool             `json:"missing,omitempty"` // Not found in the registry
+	Cycle        bool             `json:"cycle,omitempty"`   // Already on the path to this node, not expanded
+	Dependencies []dependencyNode `json:"dependencies,omitempty"`
+}
+
+// installedComponent describes where a component is installed locally.
+type installedComponent struct {
+	Ref   string   `json:"ref"`
+	Dir   string   `json:"dir"`
+	Files []string `json:"files"`
+	JS    string   `json:"js,omitempty"`
+}
+
+// runInfo handles the 'info' command logic.
+func runInfo(args []string, ref string) int {
+	if len(args) != 1 {
+		failf("Error: Specify exactly one component after 'info'.\n")
+		fmt.Println("Usage: templui info[@<ref>] <component>")
+		return exitUsage
+	}
+	name := args[0]
+	if ref == "" {
+		ref = registryRef(name, getDefaultRef())
+	}
+	result.Ref = ref
+
+	registry, err := fetchRegistryFor(name, ref)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			failf("Error: Could not fetch registry: ref '%s' not found or does not contain '%s'.\n", ref, registryPath)
+		} else {
+			failf("Error fetching registry for ref '%s': %v\n", ref, err)
+		}
+		return exitError
+	}
+	comp, ok := findComponent(registry, name)
+	if !ok {
+		failf("❌ Component '%s' not found in registry for ref '%s'.\n", name, ref)
+		return exitError
+	}
+	// Components of named registries may depend on components of the default registry.
+	if scope, _ := splitScope(name); scope != "" {
+		if defaults, err := fetchRegistry(getDefaultRef()); err == nil {
+			registry.Components = append(registry.Components, defaults.Components...)
+		}
+	}
+
+	info := componentInfo{
+		ComponentDef:   comp,
+		DocsURL:        componentDocsURL(comp),
+		DependencyTree: dependencyTree(registry, comp, []string{comp.Name}),
+		Installed:      findInstalledComponent(comp.Name),
+	}
+	result.Info = &info
+	printComponentInfo(info, ref)
+	return exitOK
+}
+
+// componentDocsURL returns the documentation URL of a component, or "" if its named registry has no documentation.
+func componentDocsURL(comp ComponentDef) string {
+	scope, _ := splitScope(comp.Name)
+	if scope == "" {
+		return componentDocsBaseURL + comp.Slug
+	}
+	if docs := namedRegistries[scope].Docs; docs != "" {
+		return strings.TrimSuffix(docs, "/") + "/" + comp.Slug
+	}
+	return ""
+}
+
+// dependencyTree returns the transitive dependencies of a component. path holds the components
+// leading to comp, so cyclic dependencies are reported instead of followed.
+func dependencyTree(registry Registry, comp ComponentDef, path []string) []dependencyNode {
+	var nodes []dependencyNode
+	for _, depName := range comp.Dependencies {
+		node := dependencyNode{Name: depName}
+		depComp, ok := findComponent(registry, depName)
+		switch {
+		case !ok:
+			node.Missing = true
+		case slices.Contains(path, depName):
+			node.Cycle = true
+		default:
+			node.Dependencies = dependencyTree(registry, depComp, append(slices.Clone(path), depName))
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// findInstalledComponent returns where a component is installed in the current project,
+// or nil if it isn't installed or there is no project.
+func findInstalledComponent(name string) *installedComponent {
+	config, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		return nil
+	}
+	installedNames, err := getInstalledComponentNames(config, lock)
+	if err != nil || !slices.Contains(installedNames, name) {
+		return nil
+	}
+
+	installed := &installedComponent{Dir: filepath.ToSlash(config.componentDir(name))}
+	installed.Ref, _ = installedComponentRef(config, lock, name)
+	installed.Files = installedComponentFiles(config, lock, name)
+	if locked, ok := lock.Components[name]; ok {
+		if locked.JS != nil {
+			installed.JS = locked.JS.Path
+		}
+		return installed
+	}
+	jsPath := componentJSPath(config, name)
+	if _, err := os.Stat(jsPath); err == nil && config.JSDir != "" {
+		installed.JS = filepath.ToSlash(jsPath)
+	}
+	return installed
+}
+
+// installedComponentFiles returns the paths of the installed files of a component, from the lockfile or,
+// for components installed without one, the files in its directory (except those generated by templ).
+func installedComponentFiles(config Config, lock *Lockfile, name string) []string {
+	var files []string
+	if locked, ok := lock.Components[name]; ok {
+		for _, file := range locked.Files {
+			files = append(files, file.Path)
+		}
+		return files
+	}
+	entries, _ := os.ReadDir(config.componentDir(name))
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasSuffix(entry.Name(), "_templ.go") {
+			files = append(files, filepath.ToSlash(filepath.Join(config.componentDir(name), entry.Name())))
+		}
+	}
+	return files
+}
+
+// printComponentInfo prints the metadata of a component.
+func printComponentInfo(info componentInfo, ref string) {
+	fmt.Printf("📦 %s (%s) - ref %s\n", info.Name, info.DisplayName, ref)
+	if info.Description != "" {
+		fmt.Printf("   %s\n", info.Description)
+	}
+	if info.DocsURL != "" {
+		fmt.Printf("\n📚 Documentation: %s\n", info.DocsURL)
+	}
+	if len(info.Categories) > 0 {
+		fmt.Printf("🗂️  Categories: %s\n", strings.Join(info.Categories, ", "))
+	}
+	if len(info.Tags) > 0 {
+		fmt.Printf("🏷️  Tags: %s\n", strings.Join(info.Tags, ", "))
+	}
+
+	js := "not required"
+	if info.HasJS {
+		js = fmt.Sprintf("required (%s)", componentJSFile(info.Name))
+	}
+	fmt.Printf("⚡ JavaScript: %s\n", js)
+
+	fmt.Println("\nFiles:")
+	for _, file := range info.Files {
+		fmt.Printf("  - %s\n", file)
+	}
+	if len(info.RequiredUtils) > 0 {
+		fmt.Println("\nRequired utils:")
+		for _, util := range info.RequiredUtils {
+			fmt.Printf("  - %s\n", util)
+		}
+	}
+
+	fmt.Println("\nDependencies:")
+	if len(info.DependencyTree) == 0 {
+		fmt.Println("  (none)")
+	} else {
+		fmt.Printf("  %s\n", info.Name)
+		printDependencyTree(info.DependencyTree, "  ")
+	}
+
+	fmt.Println()
+	if info.Installed == nil {
+		addCommand := "templui add"
+		if ref != getDefaultRef() {
+			addCommand += "@" + ref
+		}
+		fmt.Printf("Not installed. Run '%s %s' to install it.\n", addCommand, info.Name)
+		return
+	}
+	installedRef := info.Installed.Ref
+	if installedRef == "" {
+		installedRef = "unknown ref"
+	}
+	fmt.Printf("✅ Installed in %s (%s)\n", info.Installed.Dir, installedRef)
+	for _, file := range info.Installed.Files {
+		fmt.Printf("  - %s\n", file)
+	}
+	if info.Installed.JS != "" {
+		fmt.Printf("  - %s\n", info.Installed.JS)
+	}
+}
+
+// printDependencyTree prints dependency nodes with box-drawing branches.
+func printDependencyTree(nodes []dependencyNode, indent string) {
+	for i, node := range nodes {
+		branch, childIndent := "├── ", indent+"│   "
+		if i == len(nodes)-1 {
+			branch, childIndent = "└── ", indent+"    "
+		}
+		label := node.Name
+		if node.Missing {
+			label += " (not in registry)"
+		}
+		if node.Cycle {
+			label += " (cycle)"
+		}
+		fmt.Printf("%s%s%s\n", indent, branch, label)
+		printDependencyTree(node.Dependencies, childIndent)
+	}
+}
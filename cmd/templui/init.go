@@ -2,39 +2,82 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 )
 
 // runInit handles the 'init' command logic.
-func runInit(args []string, commandArg string, force bool) {
+func runInit(args []string, ref string, force bool, dryRun bool) {
 	initRef := getDefaultRef()
-
-	// Parse optional @ref from the command argument itself.
-	if strings.Contains(commandArg, "@") {
-		parts := strings.SplitN(commandArg, "@", 2)
-		if len(parts) == 2 && parts[0] == "init" && parts[1] != "" {
-			initRef = parts[1]
-			fmt.Printf("Initializing using specified ref: %s\n", initRef)
-		} else {
-			fmt.Printf("Error: Invalid format '%s'. Use 'init' or 'init@<ref>'.\n", commandArg)
-			return
-		}
-	} else if commandArg != "init" {
-		fmt.Printf("Error: Unknown command '%s'. Did you mean 'init'?\n", commandArg)
-		showHelp(nil, getDefaultRef())
-		return
+	if ref != "" {
+		initRef = ref
+		fmt.Printf("Initializing using specified ref: %s\n", initRef)
 	}
 
 	// Warn about extra arguments.
-	if len(args) > 1 {
-		fmt.Printf("Warning: Extra arguments found after '%s'. Ignoring: %v\n", commandArg, args[1:])
+	if len(args) > 0 {
+		fmt.Printf("Warning: Extra arguments found after 'init'. Ignoring: %v\n", args)
 	}
 
+	result.Ref = initRef
+
+	if dryRun {
+		planInit(initRef, force)
+		return
+	}
 	initConfig(initRef, force)
 }
 
+// planInit prints what 'init' would do, without prompting or writing anything.
+func planInit(ref string, force bool) {
+	opts := &installOptions{force: force, dryRun: true}
+
+	var existingConfig *Config
+	if configData, err := os.ReadFile(configFileName); err == nil {
+		var partialConfig Config
+		json.Unmarshal(configData, &partialConfig)
+		existingConfig = &partialConfig
+	}
+
+	switch {
+	case existingConfig != nil && !force:
+		fmt.Println("Config file already exists. Without --force, 'init' would not change anything.")
+		return
+	case existingConfig == nil:
+		opts.record(fileAction{Kind: "config", Name: configFileName, Path: configFileName, Action: actionCreate})
+	default:
+		if _, err := loadConfig(); err != nil {
+			opts.record(fileAction{Kind: "config", Name: configFileName, Path: configFileName, Action: actionOverwrite})
+		}
+	}
+
+	// Missing values would be prompted for; plan with the defaults offered by the prompts.
+	config := defaultConfig(existingConfig)
+	fmt.Printf("Planning with components in '%s', utils in '%s' and JavaScript in '%s'.\n", config.ComponentsDir, config.UtilsDir, config.JSDir)
+
+	registry, err := fetchRegistry(ref)
+	if err == nil {
+		err = checkCLIVersion(registry)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Could not fetch registry from ref '%s': %v\n", ref, err)
+		return
+	}
+	allUtilPaths := []string{}
+	for _, utilDef := range registry.Utils {
+		allUtilPaths = append(allUtilPaths, utilDef.Path)
+	}
+	err = install(config, ref, nil, allUtilPaths, opts)
+	if err != nil {
+		failf("Error planning utils installation: %v\n", err)
+		return
+	}
+
+	recordInstall(opts)
+	printInstallPlan(opts.actions)
+}
+
 // initConfig handles the creation of the config file and initial utils installation.
 func initConfig(ref string, force bool) {
 	configExists := false
@@ -48,10 +91,10 @@ func initConfig(ref string, force bool) {
 			// Check if existing config has missing fields
 			_, err := loadConfig()
 			if err != nil {
-				fmt.Println("Config file exists but has issues. Use 'templui -f init' to repair missing fields and reinstall utils.")
+				fmt.Println("Config file exists but has issues. Use 'templui init --force' to repair missing fields and reinstall utils.")
 				return
 			}
-			fmt.Println("Config file already exists and is complete. Use 'templui -f init' to reinstall utils if needed.")
+			fmt.Println("Config file already exists and is complete. Use 'templui init --force' to reinstall utils if needed.")
 			// Don't reinstall utils unless forced
 			return
 		} else {
@@ -74,9 +117,10 @@ func initConfig(ref string, force bool) {
 				// Save repaired config
 				err = saveConfig(config)
 				if err != nil {
-					fmt.Printf("Error saving repaired config file: %v\n", err)
+					failf("Error saving repaired config file: %v\n", err)
 					return
 				}
+				result.Actions = append(result.Actions, fileAction{Kind: "config", Name: configFileName, Path: configFileName, Action: actionOverwrite})
 				fmt.Println("Config file repaired successfully!")
 			} else {
 				fmt.Println("Config file is already complete.")
@@ -90,9 +134,10 @@ func initConfig(ref string, force bool) {
 
 		err := saveConfig(config)
 		if err != nil {
-			fmt.Printf("Error saving config file: %v\n", err)
+			failf("Error saving config file: %v\n", err)
 			return
 		}
+		result.Actions = append(result.Actions, fileAction{Kind: "config", Name: configFileName, Path: configFileName, Action: actionCreate})
 		fmt.Println("Config file created successfully at", configFileName)
 		fmt.Printf("Components will be installed to: %s\n", config.ComponentsDir)
 		fmt.Printf("Utils will be installed to: %s\n", config.UtilsDir)
@@ -108,15 +153,18 @@ func initConfig(ref string, force bool) {
 		// Install the default utilities.
 		config, err := loadConfig()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			failf("Error loading config: %v\n", err)
 			return
 		}
 
 		// Install all available utils from the specified ref.
 		fmt.Printf("\nAttempting to install initial utils from ref '%s'...\n", ref)
 		registry, err := fetchRegistry(ref)
+		if err == nil {
+			err = checkCLIVersion(registry)
+		}
 		if err != nil {
-			if strings.Contains(err.Error(), "status code 404") {
+			if errors.Is(err, errNotFound) {
 				fmt.Printf("Warning: Could not fetch registry from ref '%s': %v\n", ref, err)
 				fmt.Printf("  Check if the ref '%s' exists and contains the file '%s'.\n", ref, registryPath)
 			} else {
@@ -137,12 +185,26 @@ func initConfig(ref string, force bool) {
 			fmt.Printf(" - %s\n", utilDef.Path)
 		}
 
+		lock, err := loadLockfile()
+		if err != nil {
+			failf("Error loading lockfile: %v\n", err)
+			return
+		}
+
 		// Pass the force flag from the init command.
-		err = installUtils(config, allUtilPaths, ref, force)
+		opts := &installOptions{force: force, lock: lock, hashes: registry.FileHashes()}
+		err = install(config, ref, nil, allUtilPaths, opts)
+		recordInstall(opts)
 		if err != nil {
-			fmt.Printf("Error during initial utils installation: %v\n", err)
+			failf("Error during initial utils installation: %v\n", err)
 		} else {
 			fmt.Println("Initial utils installation completed.")
 		}
+		lock.markUtilsExplicit(allUtilPaths)
+
+		err = saveLockfile(lock)
+		if err != nil {
+			failf("Error updating %s: %v\n", lockFileName, err)
+		}
 	}
 }
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxConcurrentDownloads limits how many files are fetched at the same time.
+const maxConcurrentDownloads = 8
+
+// plannedFile is a file written by an installation, together with what happens to it
+// and, once fetched, its content.
+type plannedFile struct {
+	fileAction
+	comp     ComponentDef // Component the file belongs to (zero for utils)
+	repoPath string       // Path relative to the repository root
+
+	data     []byte // Content fetched from the registry
+	fetchErr error
+	baseData []byte // Content of the installed version, fetched when merging
+	baseErr  error
+
+	lockSHA string // Hash to lock instead of the content on disk, e.g., of the registry version of a merged file
+}
+
+// componentInstall groups the planned files of a single component: its files followed by its JavaScript file.
+type componentInstall struct {
+	comp  ComponentDef
+	files []*plannedFile
+}
+
+// errDependencyCycle is returned when components of the registry depend on each other in a cycle.
+var errDependencyCycle = errors.New("dependency cycle")
+
+// resolveComponents returns the requested components together with all their dependencies, ordered so that
+// dependencies come before the components that need them, and the sorted paths of the utils they require.
+// Dependencies that form a cycle can't be ordered and are reported as an error.
+func resolveComponents(names []string, componentMap map[string]ComponentDef, ref string) ([]ComponentDef, []string, error) {
+	var ordered []ComponentDef
+	visited := make(map[string]bool)
+	var path []string // Components being visited, from the requested one to the current one.
+	var visit func(comp ComponentDef) error
+	visit = func(comp ComponentDef) error {
+		if i := slices.Index(path, comp.Name); i >= 0 {
+			cycle := append(slices.Clone(path[i:]), comp.Name)
+			return fmt.Errorf("%w in registry for ref '%s': %s", errDependencyCycle, ref, strings.Join(cycle, " → "))
+		}
+		if visited[comp.Name] {
+			return nil
+		}
+		path = append(path, comp.Name)
+		for _, depName := range comp.Dependencies {
+			depComp, exists := componentMap[depName]
+			if !exists {
+				fmt.Printf("Warning: Dependency '%s' for component '%s' not found in registry for ref '%s'. Skipping dependency.\n", depName, comp.Name, ref)
+				continue
+			}
+			err := visit(depComp)
+			if err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visited[comp.Name] = true
+		ordered = append(ordered, comp)
+		return nil
+	}
+	for _, name := range names {
+		if comp, exists := componentMap[name]; exists {
+			err := visit(comp)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	var requiredUtils []string
+	for _, comp := range ordered {
+		for _, repoUtilPath := range comp.RequiredUtils {
+			if !slices.Contains(requiredUtils, repoUtilPath) {
+				requiredUtils = append(requiredUtils, repoUtilPath)
+			}
+		}
+	}
+	slices.Sort(requiredUtils)
+	return ordered, requiredUtils, nil
+}
+
+// install installs components (already resolved with resolveComponents) and utils in three phases:
+// every file is planned and all overwrite prompts are answered first, then the files are fetched
+// concurrently, and finally they are staged and moved into place together. In dry-run mode only the plan is recorded.
+// If any component or util fails, no file is written and the failures are returned joined.
+func install(config Config, ref string, comps []ComponentDef, utilPaths []string, opts *installOptions) error {
+	components, utils := planInstall(config, ref, comps, utilPaths, opts)
+
+	if opts.dryRun {
+		for _, ci := range components {
+			for _, file := range ci.files {
+				opts.record(file.fileAction)
+			}
+			if ci.comp.HasJS && config.JSDir != "" {
+				addScriptTemplateToFiles(config, ci.comp, componentJSFile(ci.comp.Name), opts, nil)
+			}
+		}
+		for _, file := range utils {
+			opts.record(file.fileAction)
+		}
+		return nil
+	}
+
+	// Ask all questions up front, so downloads aren't interrupted by prompts.
+	var allFiles []*plannedFile
+	for _, ci := range components {
+		allFiles = append(allFiles, ci.files...)
+	}
+	allFiles = append(allFiles, utils...)
+	for _, file := range allFiles {
+		if file.Action == actionPrompt {
+			file.Action = actionKeep
+			if confirmOverwrite(file) {
+				file.Action = actionOverwrite
+			}
+		}
+	}
+
+	fetchPlannedFiles(allFiles, ref, opts)
+
+	// Render every file into a staging directory first, so that a failed download or render
+	// leaves the project untouched.
+	tx := newInstallTransaction(opts.keepBackups)
+	defer tx.discard()
+	var errs []error
+	for _, ci := range components {
+		err := stageComponent(config, ci, ref, opts, tx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("component %s: %w", ci.comp.Name, err))
+		}
+	}
+	if len(utils) > 0 {
+		fmt.Printf("\n🛠️  Installing utils in: %s (from ref: %s)\n", config.UtilsDir, ref)
+	}
+	for _, file := range utils {
+		err := stagePlannedFile(config, file, opts, tx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("util %s: %w", file.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Println("\n❌ Installation failed, no files were changed.")
+		return errors.Join(errs...)
+	}
+
+	err := tx.commit()
+	if err != nil {
+		return fmt.Errorf("staged files: %w", err)
+	}
+	return lockInstalled(config, ref, components, utils, opts)
+}
+
+// planInstall decides what happens to every file of the components and utils, based on their existing versions.
+// Components and utils of named registries are installed from the ref configured for their registry.
+func planInstall(config Config, ref string, comps []ComponentDef, utilPaths []string, opts *installOptions) ([]componentInstall, []*plannedFile) {
+	var components []componentInstall
+	for _, comp := range comps {
+		ref := registryRef(comp.Name, ref)
+		ci := componentInstall{comp: comp}
+		for _, repoFilePath := range comp.Files {
+			// Determine the destination path, preserving subdirectory structure.
+			destPath, ok := componentDestPath(config, repoFilePath)
+			if !ok {
+				// Fallback for unexpected paths (shouldn't happen with proper manifest).
+				fmt.Printf("  Warning: File path '%s' does not start with '%s'. Placing it directly in '%s'.\n", repoFilePath, repoComponentBasePath, config.ComponentsDir)
+			}
+			action, existingRef := planFileWrite(destPath, ref, opts, true)
+			ci.files = append(ci.files, &plannedFile{
+				fileAction: fileAction{Kind: "component", Name: comp.Name, Path: destPath, Action: action, Ref: ref, ExistingRef: existingRef},
+				comp:       comp,
+				repoPath:   repoFilePath,
+			})
+		}
+
+		// JavaScript files carry no version comment, so existing ones are overwritten with --force or after asking.
+		if comp.HasJS && config.JSDir != "" {
+			jsDestPath := componentJSPath(config, comp.Name)
+			action := actionCreate
+			if _, err := os.Stat(jsDestPath); err == nil {
+				action = actionOverwrite
+				if !opts.force {
+					action = actionPrompt
+				}
+			}
+			ci.files = append(ci.files, &plannedFile{
+				fileAction: fileAction{Kind: "js", Name: comp.Name, Path: jsDestPath, Action: action, Ref: ref},
+				comp:       comp,
+				repoPath:   componentJSRepoPath(comp.Name),
+			})
+		}
+		components = append(components, ci)
+	}
+
+	var utils []*plannedFile
+	seen := make(map[string]bool)
+	for _, repoUtilPath := range utilPaths {
+		if seen[repoUtilPath] {
+			continue
+		}
+		seen[repoUtilPath] = true
+
+		// Determine destination path, preserving subdirectory structure.
+		destPath, ok := utilDestPath(config, repoUtilPath)
+		if !ok {
+			fmt.Printf("  Warning: Util path '%s' does not start with '%s'. Placing it directly in '%s'.\n", repoUtilPath, repoUtilBasePath, filepath.Dir(destPath))
+		}
+		ref := registryRef(repoUtilPath, ref)
+		action, existingRef := planFileWrite(destPath, ref, opts, false)
+		utils = append(utils, &plannedFile{
+			fileAction: fileAction{Kind: "util", Name: repoUtilPath, Path: destPath, Action: action, Ref: ref, ExistingRef: existingRef},
+			repoPath:   repoUtilPath,
+		})
+	}
+	return components, utils
+}
+
+// confirmOverwrite asks the user whether an existing file may be overwritten.
+func confirmOverwrite(file *plannedFile) bool {
+	if file.Kind != "js" {
+		return askForOverwrite(file.Path, file.ExistingRef, file.Ref)
+	}
+	fmt.Printf("   JavaScript file '%s' already exists. Overwrite? (y/N): ", file.Path)
+	var response string
+	fmt.Scanln(&response)
+	return strings.ToLower(strings.TrimSpace(response)) == "y"
+}
+
+// fetchPlannedFiles downloads the content of all files that get written, using a bounded
+// number of concurrent downloads and reporting progress.
+func fetchPlannedFiles(files []*plannedFile, ref string, opts *installOptions) {
+	var toFetch []*plannedFile
+	for _, file := range files {
+		if file.Action == actionCreate || file.Action == actionOverwrite || file.Action == actionMerge {
+			toFetch = append(toFetch, file)
+		}
+	}
+	total := len(toFetch)
+	if total == 0 {
+		return
+	}
+	fmt.Printf("\n⬇️  Downloading %d file(s) from %s\n", total, source.location(ref, ""))
+	if len(opts.hashes) == 0 {
+		fmt.Println("   ⚠️  The registry publishes no SHA-256 hashes, downloaded files can't be verified.")
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentDownloads)
+
+	statusChan := make(chan bool) // Whether a fetch succeeded
+	reporterDone := make(chan struct{})
+
+	// Start progress reporter goroutine, which finishes the progress line once statusChan is closed.
+	go func() {
+		defer close(reporterDone)
+		processed, failed := 0, 0
+		for ok := range statusChan {
+			processed++
+			if !ok {
+				failed++
+			}
+			fmt.Printf("\r   Downloading files... [%d/%d]", processed, total)
+		}
+		if failed > 0 {
+			fmt.Printf("\r   Downloading files... [%d/%d] %d failed!\n", processed, total, failed)
+		} else {
+			fmt.Printf("\r   Downloading files... [%d/%d] Complete!\n", processed, total)
+		}
+	}()
+
+	for _, file := range toFetch {
+		wg.Add(1)
+		go func(file *plannedFile) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() {
+				<-semaphore
+			}()
+
+			file.data, file.fetchErr = fetchVerified(file.Ref, file.repoPath, opts.hashes)
+			if file.Action == actionMerge {
+				file.baseData, file.baseErr = source.fetch(file.ExistingRef, file.repoPath)
+			}
+			statusChan <- file.fetchErr == nil
+		}(file)
+	}
+
+	wg.Wait()
+	close(statusChan)
+	<-reporterDone
+}
+
+// stageComponent stages the fetched files of a component and adds the Script() template
+// for components with JavaScript.
+func stageComponent(config Config, ci componentInstall, ref string, opts *installOptions, tx *installTransaction) error {
+	comp := ci.comp
+	fmt.Printf("\n📦 Installing component: %s (from ref: %s)\n", comp.Name, registryRef(comp.Name, ref))
+
+	for _, file := range ci.files {
+		err := stagePlannedFile(config, file, opts, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if comp.HasJS && config.JSDir != "" {
+		err := addScriptTemplateToFiles(config, comp, componentJSFile(comp.Name), opts, tx)
+		if err != nil {
+			return fmt.Errorf("failed to add Script() template: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockInstalled records the written components and utils in the lockfile, unless the user kept
+// some of their older files.
+func lockInstalled(config Config, ref string, components []componentInstall, utils []*plannedFile, opts *installOptions) error {
+	if opts.lock == nil {
+		return nil
+	}
+	for _, ci := range components {
+		skippedByUser := false // Whether the user declined overwriting any file of this component.
+		var destPaths []string
+		for _, file := range ci.files {
+			if file.Kind == "component" {
+				destPaths = append(destPaths, file.Path)
+				if file.Action == actionKeep {
+					skippedByUser = true
+				}
+			}
+		}
+		if skippedByUser {
+			continue
+		}
+
+		// Files that weren't written keep the hash locked for their ref, so local edits still show up as modified.
+		previous, wasLocked := opts.lock.Components[ci.comp.Name]
+		lockSHAs := make(map[string]string)
+		for _, file := range ci.files {
+			if file.Action == actionSkip && wasLocked && previous.Ref == file.Ref {
+				file.lockSHA = previous.fileSHA(file.Path)
+			}
+			if file.lockSHA != "" {
+				lockSHAs[file.Path] = file.lockSHA
+			}
+		}
+		err := lockComponent(opts.lock, config, ci.comp, registryRef(ci.comp.Name, ref), destPaths, lockSHAs)
+		if err != nil {
+			return err
+		}
+	}
+	for _, file := range utils {
+		if file.Action == actionKeep {
+			continue // Keep the existing lockfile entry for the old version.
+		}
+		if previous, ok := opts.lock.Utils[file.repoPath]; ok && file.Action == actionSkip && previous.Ref == file.Ref {
+			continue // Not written, keep the hash locked for this ref.
+		}
+		lockedFile, err := lockedFileFor(file.Path, source.location(file.Ref, file.repoPath))
+		if err != nil {
+			return err
+		}
+		opts.lock.setUtil(file.repoPath, LockedUtil{Ref: file.Ref, Commit: resolveCommit(file.repoPath, file.Ref), LockedFile: lockedFile})
+	}
+	return nil
+}
+
+// stagePlannedFile renders the fetched content of a file for the project and stages it,
+// merging local changes into component files if requested.
+func stagePlannedFile(config Config, file *plannedFile, opts *installOptions, tx *installTransaction) error {
+	destPath, ref := file.Path, file.Ref
+
+	var mergeBase []byte // Pristine content of the installed version when merging.
+	if file.Action == actionMerge {
+		var ok bool
+		mergeBase, ok = renderMergeBase(config, file.comp, file.repoPath, destPath, file.ExistingRef, file.baseData, file.baseErr)
+		if !ok {
+			file.Action = actionOverwrite
+			if !opts.force && !confirmOverwrite(file) {
+				file.Action = actionKeep
+			}
+		}
+	}
+
+	switch file.Action {
+	case actionSkip:
+		fmt.Printf("      ℹ️  File '%s' already up-to-date (ref: %s). Skipping.\n", destPath, ref)
+	case actionKeep:
+		fmt.Printf("      ⏭️  Skipping overwrite for '%s'.\n", destPath)
+	case actionMerge:
+		fmt.Printf("      🔀 File '%s' exists (Version: '%s'). Merging local changes with ref '%s'.\n", destPath, file.ExistingRef, ref)
+	case actionOverwrite:
+		if file.ExistingRef == ref {
+			fmt.Printf("      ⚠️  File '%s' already up-to-date (ref: %s). Forcing overwrite.\n", destPath, ref)
+		} else if file.ExistingRef != "" && opts.force {
+			fmt.Printf("      ⚠️  File '%s' exists (Version: '%s'). Forcing overwrite with ref '%s'.\n", destPath, file.ExistingRef, ref)
+		} else if file.ExistingRef != "" {
+			fmt.Printf("      ⚠️  File '%s' exists (Version: '%s'). Overwriting with ref '%s'.\n", destPath, file.ExistingRef, ref)
+		}
+	}
+	opts.record(file.fileAction)
+
+	// Proceed with writing only if necessary.
+	if file.Action == actionSkip || file.Action == actionKeep {
+		return nil
+	}
+	if file.fetchErr != nil {
+		return fmt.Errorf("failed to download file '%s' from %s: %w", filepath.Base(file.repoPath), source.location(ref, file.repoPath), file.fetchErr)
+	}
+	if len(opts.hashes) > 0 && opts.hashes[file.repoPath] == "" {
+		fmt.Printf("      ⚠️  No SHA-256 published for '%s', installing it unverified.\n", file.repoPath)
+	}
+
+	data := file.data
+	switch file.Kind {
+	case "component":
+		// Add version comment with documentation link and replace imports.
+		rendered, importsAdjusted, err := renderComponentFile(data, config, file.comp, ref, file.repoPath)
+		if err != nil {
+			return err
+		}
+		if importsAdjusted {
+			logImportAdjustment(file.comp.Name)
+		}
+		data = rendered
+		if file.Action == actionMerge {
+			theirs, err := renderPristineComponentFile(file.data, config, file.comp, ref, file.repoPath)
+			if err != nil {
+				return err
+			}
+			// Lock the registry version, so the local changes merged into it show up as modified.
+			file.lockSHA = hashContent(theirs)
+			merged, err := mergeComponentFile(destPath, mergeBase, theirs, file.ExistingRef, ref, opts)
+			if err != nil {
+				return err
+			}
+			data = merged
+		}
+	case "util":
+		// Add version comment and replace imports.
+		utilNameForComment := filepath.Base(file.repoPath)
+		versionComment := fmt.Sprintf("// templui util %s - version: %s installed by templui %s\n", utilNameForComment, ref, version)
+		if strings.HasSuffix(file.repoPath, ".go") {
+			scope, _ := splitScope(file.repoPath)
+			var err error
+			data, err = replaceImports(data, config, scope, "")
+			if err == nil {
+				// Rename the package to match the destination directory name
+				data, err = renamePackage(data, "utils", utilPackageName(config, file.repoPath))
+			}
+			if err != nil {
+				return fmt.Errorf("failed to rewrite '%s': %w", file.repoPath, err)
+			}
+		}
+		data = append([]byte(versionComment), data...)
+	}
+
+	return tx.stage(destPath, data)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRefServer serves files as a registry source laid out as <ref>/<path>.
+func newRefServer(t *testing.T, files map[string]map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ref, repoPath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		content, ok := files[ref][repoPath]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// useSource replaces the registry source for the rest of the test.
+func useSource(t *testing.T, src registrySource) {
+	t.Helper()
+	previous := source
+	source = src
+	t.Cleanup(func() { source = previous })
+}
+
+// newTestProject changes into an empty project directory and returns its config and an empty lockfile.
+func newTestProject(t *testing.T) (Config, *Lockfile) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	lock, err := loadLockfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{ComponentsDir: "components", UtilsDir: "utils", JSDir: "assets/js", JSPublicPath: "/assets/js", ModuleName: "example.com/app"}, lock
+}
+
+func TestLockfileKeepsLocalEditsModified(t *testing.T) {
+	const buttonPath = "internal/components/button/button.templ"
+	v1 := "package button\n\ntempl Button() {\n\t<button>v1</button>\n}\n"
+	v2 := "package button\n\ntempl Button() {\n\t<button class=\"v2\">v2</button>\n}\n"
+	server := newRefServer(t, map[string]map[string]string{
+		"v1": {buttonPath: v1},
+		"v2": {buttonPath: v2},
+	})
+	useSource(t, httpSource{baseURL: server.URL + "/"})
+	config, lock := newTestProject(t)
+	button := ComponentDef{Name: "button", Files: []string{buttonPath}}
+	destPath := filepath.Join("components", "button", "button.templ")
+	lockedSHA := func() string { return lock.Components["button"].Files[0].SHA256 }
+	verify := func() string {
+		return verifyFile(filepath.ToSlash(destPath), lockedSHA(), "", func(data []byte) []byte { return data })
+	}
+
+	if err := install(config, "v1", []ComponentDef{button}, nil, &installOptions{lock: lock}); err != nil {
+		t.Fatalf("install v1: %v", err)
+	}
+	installed, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockedSHA() != hashContent(installed) {
+		t.Fatal("installed file isn't locked with its hash")
+	}
+	if status := verify(); status != verifyOK {
+		t.Errorf("fresh install verifies as %s, want %s", status, verifyOK)
+	}
+
+	// Edit the file and add the component again at the same ref: the file is skipped.
+	edited := string(installed) + "// local edit\n"
+	if err := os.WriteFile(destPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := &installOptions{lock: lock}
+	if err := install(config, "v1", []ComponentDef{button}, nil, opts); err != nil {
+		t.Fatalf("install v1 again: %v", err)
+	}
+	if opts.actions[0].Action != actionSkip {
+		t.Fatalf("second install action = %s, want %s", opts.actions[0].Action, actionSkip)
+	}
+	if lockedSHA() != hashContent(installed) {
+		t.Error("skipped file was re-locked with the local edit")
+	}
+	if status := verify(); status != verifyModified {
+		t.Errorf("edited file after skip verifies as %s, want %s", status, verifyModified)
+	}
+
+	// Merge the edit into v2: the registry version is locked, not the merged file.
+	opts = &installOptions{merge: true, lock: lock}
+	if err := install(config, "v2", []ComponentDef{button}, nil, opts); err != nil {
+		t.Fatalf("install v2 with merge: %v", err)
+	}
+	if len(opts.merged) != 1 {
+		t.Fatalf("merged = %v, conflicts = %v, want one clean merge", opts.merged, opts.conflicts)
+	}
+	merged, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(merged), "// local edit") || !strings.Contains(string(merged), `class="v2"`) {
+		t.Fatalf("merged file lacks the local edit or the update:\n%s", merged)
+	}
+	pristine, err := renderPristineComponentFile([]byte(v2), config, button, "v2", buttonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockedSHA() != hashContent(pristine) {
+		t.Error("merged file isn't locked with the hash of the registry version")
+	}
+	if status := verify(); status != verifyModified {
+		t.Errorf("merged file verifies as %s, want %s", status, verifyModified)
+	}
+}
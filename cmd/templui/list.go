@@ -1,75 +1,77 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 )
 
-// runList handles the 'list' command logic.
-func runList(args []string, commandArg string) {
-	listRef := getDefaultRef()
+// runList handles the 'list' command logic. Components can be filtered by category and tag,
+// and 'list @acme' lists the components of a named registry.
+func runList(args []string, ref string, categories, tags []string) {
+	scope := ""
+	if len(args) == 1 && registryNameRegex.MatchString(args[0]) {
+		scope, args = args[0], nil
+	}
 
-	// Parse optional @ref from the command argument.
-	if strings.Contains(commandArg, "@") {
-		parts := strings.SplitN(commandArg, "@", 2)
-		if len(parts) == 2 && parts[0] == "list" && parts[1] != "" {
-			listRef = parts[1]
-			fmt.Printf("Listing components using specified ref: %s\n", listRef)
-		} else {
-			fmt.Printf("Error: Invalid format '%s'. Use 'list' or 'list@<ref>'.\n", commandArg)
-			return
-		}
-	} else if commandArg != "list" {
-		fmt.Printf("Error: Unknown command '%s'. Did you mean 'list'?\n", commandArg)
-		showHelp(nil, getDefaultRef())
-		return
+	listRef := getDefaultRef()
+	if scope != "" {
+		listRef = namedRegistryRef(scope)
+	}
+	if ref != "" {
+		listRef = ref
+		fmt.Printf("Listing components using specified ref: %s\n", listRef)
 	}
 
+	result.Ref = listRef
+
 	// Warn about extra arguments.
-	if len(args) > 1 {
-		fmt.Printf("Warning: Extra arguments found after '%s'. Ignoring: %v\n", commandArg, args[1:])
+	if len(args) > 0 {
+		fmt.Printf("Warning: Extra arguments found after 'list'. Ignoring: %v\n", args)
 	}
 
-	err := listComponents(listRef)
+	err := listComponents(scope, listRef, categories, tags)
 	if err != nil {
-		fmt.Printf("Error listing components: %v\n", err)
+		failf("Error listing components: %v\n", err)
 	}
 }
 
-// listComponents fetches the registry and lists available components and utils.
-func listComponents(ref string) error {
+// listComponents fetches the registry (or the named registry scope) and lists available components and utils.
+// With filters, only the matching components are listed.
+func listComponents(scope, ref string, categories, tags []string) error {
 	fmt.Printf("Fetching component registry from ref '%s'...\n", ref)
-	registry, err := fetchRegistry(ref)
+	registry, err := fetchRegistryFor(qualifiedName(scope, ""), ref)
 	if err != nil {
-		if strings.Contains(err.Error(), "status code 404") {
+		if errors.Is(err, errNotFound) {
 			return fmt.Errorf("could not fetch registry: ref '%s' not found or does not contain '%s'", ref, registryPath)
 		}
 		return fmt.Errorf("could not fetch registry: %w", err)
 	}
 
-	fmt.Printf("\nAvailable components in ref '%s':\n", ref)
-	if len(registry.Components) == 0 {
-		fmt.Println("  No components found in this registry.")
+	filtered := len(categories) > 0 || len(tags) > 0
+	components := registry.Filter(categories, tags)
+	result.Components = components
+	if !filtered {
+		result.Utils = registry.Utils
+	}
+
+	fmt.Printf("\nAvailable components in ref '%s'%s:\n", ref, describeFilters(categories, tags))
+	if len(components) == 0 {
+		if filtered {
+			fmt.Println("  No components match the given category or tag.")
+		} else {
+			fmt.Println("  No components found in this registry.")
+		}
 	} else {
 		// Print components.
-		for _, comp := range registry.Components {
-			desc := comp.Description
-			if len(desc) > 45 {
-				desc = desc[:42] + "..."
-			}
-
-			jsStatus := ""
-			if comp.HasJS {
-				jsStatus = " [JS]"
-			}
-
-			fmt.Printf("  - %-20s : %s%s\n", comp.Name, desc, jsStatus)
+		for _, comp := range components {
+			printComponentLine(comp)
 		}
 	}
 
 	// Print utils.
-	if len(registry.Utils) > 0 {
+	if len(registry.Utils) > 0 && !filtered {
 		fmt.Printf("\nAvailable utils in ref '%s':\n", ref)
 		for _, util := range registry.Utils {
 			utilName := filepath.Base(util.Path)
@@ -87,3 +89,44 @@ func listComponents(ref string) error {
 
 	return nil
 }
+
+// printComponentLine prints a component with its shortened description in the list format.
+func printComponentLine(comp ComponentDef) {
+	desc := comp.Description
+	if len(desc) > 45 {
+		desc = desc[:42] + "..."
+	}
+
+	jsStatus := ""
+	if comp.HasJS {
+		jsStatus = " [JS]"
+	}
+
+	fmt.Printf("  - %-20s : %s%s\n", comp.Name, desc, jsStatus)
+}
+
+// describeFilters describes the category and tag filters for a heading, "" without filters.
+func describeFilters(categories, tags []string) string {
+	var parts []string
+	if len(categories) > 0 {
+		parts = append(parts, "category: "+strings.Join(categories, ", "))
+	}
+	if len(tags) > 0 {
+		parts = append(parts, "tag: "+strings.Join(tags, ", "))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+const (
+	lockFileName    = ".templui.lock"
+	lockfileVersion = 1
+)
+
+// Lockfile defines the structure of the .templui.lock file, which records
+// exactly what was installed into the project.
+type Lockfile struct {
+	LockfileVersion int                        `json:"lockfileVersion"`
+	Components      map[string]LockedComponent `json:"components"`
+	Utils           map[string]LockedUtil      `json:"utils"` // Keyed by the util path relative to the repository root
+}
+
+// LockedComponent records an installed component.
+type LockedComponent struct {
+	Ref           string       `json:"ref"`
+	Commit        string       `json:"commit,omitempty"`        // Commit the ref resolved to at install time
+	Explicit      bool         `json:"explicit,omitempty"`      // Whether the component was requested directly (not only as a dependency)
+	Dependencies  []string     `json:"dependencies,omitempty"`  // Names of other required components
+	RequiredUtils []string     `json:"requiredUtils,omitempty"` // Paths to required utils relative to the repository root
+	Files         []LockedFile `json:"files"`
+	JS            *LockedFile  `json:"js,omitempty"`
+}
+
+// LockedUtil records an installed utility file.
+type LockedUtil struct {
+	Ref      string `json:"ref"`
+	Commit   string `json:"commit,omitempty"`
+	Explicit bool   `json:"explicit,omitempty"` // Whether the util was installed directly (by init, new or upgrade) rather than required by a component
+	LockedFile
+}
+
+// LockedFile records a single installed file.
+type LockedFile struct {
+	Path   string `json:"path"`   // Destination path relative to the project root
+	Source string `json:"source"` // Location the file was fetched from
+	SHA256 string `json:"sha256"` // Hash of the installed file content
+}
+
+// loadLockfile reads .templui.lock, returning an empty lockfile if it doesn't exist yet.
+func loadLockfile() (*Lockfile, error) {
+	lock := &Lockfile{
+		LockfileVersion: lockfileVersion,
+		Components:      make(map[string]LockedComponent),
+		Utils:           make(map[string]LockedUtil),
+	}
+
+	data, err := os.ReadFile(lockFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("error reading lockfile: %w", err)
+	}
+
+	err = json.Unmarshal(data, lock)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing lockfile %s: %w", lockFileName, err)
+	}
+	if lock.LockfileVersion > lockfileVersion {
+		return nil, fmt.Errorf("lockfile %s has version %d, but this CLI only supports version %d. Run 'templui upgrade'", lockFileName, lock.LockfileVersion, lockfileVersion)
+	}
+	if lock.Components == nil {
+		lock.Components = make(map[string]LockedComponent)
+	}
+	if lock.Utils == nil {
+		lock.Utils = make(map[string]LockedUtil)
+	}
+	return lock, nil
+}
+
+// saveLockfile writes the lockfile to .templui.lock.
+func saveLockfile(lock *Lockfile) error {
+	lock.LockfileVersion = lockfileVersion
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error creating lockfile data: %w", err)
+	}
+	err = os.WriteFile(lockFileName, append(data, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("error saving lockfile: %w", err)
+	}
+	return nil
+}
+
+// componentNames returns the names of all locked components, sorted.
+func (l *Lockfile) componentNames() []string {
+	names := make([]string, 0, len(l.Components))
+	for name := range l.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// utilPaths returns the repository paths of all locked utils, sorted.
+func (l *Lockfile) utilPaths() []string {
+	paths := make([]string, 0, len(l.Utils))
+	for repoUtilPath := range l.Utils {
+		paths = append(paths, repoUtilPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// setComponent records a component, preserving whether it was previously requested explicitly.
+func (l *Lockfile) setComponent(name string, locked LockedComponent) {
+	if existing, ok := l.Components[name]; ok && existing.Explicit {
+		locked.Explicit = true
+	}
+	l.Components[name] = locked
+}
+
+// markExplicit flags a locked component as requested directly by the user.
+func (l *Lockfile) markExplicit(name string) {
+	if locked, ok := l.Components[name]; ok {
+		locked.Explicit = true
+		l.Components[name] = locked
+	}
+}
+
+// setUtil records a util, preserving whether it was previously installed directly.
+func (l *Lockfile) setUtil(repoUtilPath string, locked LockedUtil) {
+	if existing, ok := l.Utils[repoUtilPath]; ok && existing.Explicit {
+		locked.Explicit = true
+	}
+	l.Utils[repoUtilPath] = locked
+}
+
+// markUtilsExplicit flags locked utils as installed directly by the user.
+func (l *Lockfile) markUtilsExplicit(repoUtilPaths []string) {
+	for _, repoUtilPath := range repoUtilPaths {
+		if locked, ok := l.Utils[repoUtilPath]; ok {
+			locked.Explicit = true
+			l.Utils[repoUtilPath] = locked
+		}
+	}
+}
+
+// fileSHA returns the locked hash of an installed file of the component, or "" if it isn't locked.
+func (c LockedComponent) fileSHA(path string) string {
+	for _, file := range c.Files {
+		if file.Path == filepath.ToSlash(path) {
+			return file.SHA256
+		}
+	}
+	if c.JS != nil && c.JS.Path == filepath.ToSlash(path) {
+		return c.JS.SHA256
+	}
+	return ""
+}
+
+// lockedFileFor hashes an installed file and records where it was fetched from.
+func lockedFileFor(path, location string) (LockedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LockedFile{}, fmt.Errorf("failed to read installed file '%s': %w", path, err)
+	}
+	return LockedFile{
+		Path:   filepath.ToSlash(path),
+		Source: location,
+		SHA256: hashContent(data),
+	}, nil
+}
+
+// hashContent returns the hex-encoded SHA-256 hash of data.
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// commitSHARegex matches full git commit hashes.
+var commitSHARegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// resolvedCommits caches ref to commit resolution for the current run, keyed by registry and ref.
+var resolvedCommits = make(map[string]string)
+
+// resolveCommit returns the commit a ref points to in the source of the registry a component
+// or repository path comes from. An empty string is returned if the source cannot resolve refs.
+func resolveCommit(name, ref string) string {
+	if commitSHARegex.MatchString(ref) {
+		return ref
+	}
+	scope, _ := splitScope(name)
+	key := qualifiedName(scope, ref)
+	if commit, ok := resolvedCommits[key]; ok {
+		return commit
+	}
+	commit, err := sourceFor(scope).commit(ref)
+	if err != nil {
+		fmt.Printf("   Warning: Could not resolve commit for ref '%s': %v\n", ref, err)
+	}
+	resolvedCommits[key] = commit
+	return commit
+}
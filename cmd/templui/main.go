@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
@@ -12,7 +14,8 @@ const (
 	configFileName = ".templui.json"
 	registryPath   = "internal/registry/registry.json" // Path to the registry within the repository
 	// Base URL for fetching raw file content.
-	rawContentBaseURL = "https://raw.githubusercontent.com/templui/templui/"
+	githubRawHost     = "https://raw.githubusercontent.com/"
+	rawContentBaseURL = githubRawHost + "templui/templui/"
 )
 
 // getVersion returns the version from build info or dev version for local builds.
@@ -58,11 +61,27 @@ func getDefaultRef() string {
 
 // Flags defined for the command line interface.
 var (
-	forceOverwrite = flag.Bool("force", false, "Force overwrite existing files without asking")
-	versionFlag    = flag.Bool("version", false, "Show installer version")
-	helpFlag       = flag.Bool("help", false, "Show this help message")
-	moduleFlag     = flag.String("module", "", "Go module name (for 'new' command)")
-	installedFlag  = flag.Bool("installed", false, "Update all currently installed components")
+	forceOverwrite  = flag.Bool("force", false, "Force overwrite existing files without asking")
+	versionFlag     = flag.Bool("version", false, "Show installer version")
+	helpFlag        = flag.Bool("help", false, "Show this help message")
+	moduleFlag      = flag.String("module", "", "Go module name (for 'new' command)")
+	installedFlag   = flag.Bool("installed", false, "Update all currently installed components")
+	pruneFlag       = flag.Bool("prune", false, "Remove dependencies and utils no longer needed (for 'remove' command)")
+	mergeFlag       = flag.Bool("merge", false, "Three-way merge local changes into updated components instead of overwriting")
+	registryFlag    = flag.String("registry", "", "Registry source: local directory, file:// URL or HTTP base URL (overrides .templui.json)")
+	dryRunFlag      = flag.Bool("dry-run", false, "Show what would be created or changed without writing anything")
+	offlineFlag     = flag.Bool("offline", false, "Only use cached registry and component files, without network access")
+	keepBackupsFlag = flag.Bool("keep-backups", false, "Keep files replaced by 'add' or 'upgrade' as <file>.orig")
+	refFlag         = flag.String("ref", "", "Ref (branch, tag or commit) to use, same as <command>@<ref>")
+	outputFlag      = flag.String("output", outputText, "Output format: 'text' or 'json' (JSON result on stdout, progress on stderr)")
+	categoryFlag    = flag.String("category", "", "Only show components in these categories, comma-separated (for 'list' and 'search')")
+	tagFlag         = flag.String("tag", "", "Only show components with any of these tags, comma-separated (for 'list' and 'search')")
+	formatFlag      = flag.String("format", graphDOT, "Graph format: 'dot' or 'mermaid' (for 'graph' command)")
+	checkFlag       = flag.Bool("check", false, "Only check that registry.json is up to date, without writing it (for 'registry build')")
+	hashesFlag      = flag.Bool("hashes", false, "Publish the SHA-256 hashes of all files in registry.json (for 'registry build')")
+	goInstallFlag   = flag.Bool("go-install", false, "Upgrade the CLI with 'go install' instead of downloading the release binary (for 'upgrade')")
+	timeoutFlag     = flag.Duration("timeout", defaultHTTPTimeout, "Timeout of each HTTP request, e.g. '1m'")
+	releaseURLFlag  = flag.String("release-url", "", "Base URL of the release archives, as <base><tag>/ or with a {ref} placeholder (for 'upgrade', default: $TEMPLUI_RELEASE_URL or GitHub releases)")
 )
 
 func main() {
@@ -71,14 +90,70 @@ func main() {
 	}
 	flag.Parse()
 
+	os.Exit(finishCommand(*outputFlag, runCommand()))
+}
+
+// runCommand runs the command given on the command line and returns its exit code.
+func runCommand() int {
+	args := flag.Args()
+
 	// Handle version display.
-	if *versionFlag {
-		fmt.Printf("templUI %s\n", version)
-		return
+	if *versionFlag && len(args) == 0 {
+		args = []string{"version"}
+	}
+
+	// Handle help display: 'templui --help <command>' shows the help of the command.
+	if *helpFlag && len(args) > 0 {
+		args = []string{"help", strings.SplitN(args[0], "@", 2)[0]}
+		*helpFlag = false
+	}
+
+	var inv invocation
+	if len(args) > 0 {
+		var err error
+		inv, err = parseInvocation(args)
+		if errors.Is(err, flag.ErrHelp) {
+			result.Command = "help"
+			printCommandHelp(inv.cmd)
+			return exitOK
+		}
+		if err != nil {
+			failf("Error: %v\n", err)
+			if inv.cmd != nil {
+				fmt.Printf("Run 'templui help %s' for usage.\n", inv.cmd.name)
+			} else {
+				fmt.Println("Run 'templui help' for usage.")
+			}
+			return exitUsage
+		}
+		result.Command = inv.cmd.name
+	}
+
+	// Flags after the command may have changed the output format.
+	err := setOutputFormat(*outputFlag)
+	if err != nil {
+		failf("Error: %v\n", err)
+		return exitUsage
+	}
+
+	if *timeoutFlag <= 0 {
+		failf("Error: --timeout must be positive\n")
+		return exitUsage
+	}
+	httpClient = newHTTPClient(*timeoutFlag)
+
+	// Select where the registry and component files are fetched from, unless the command doesn't use them.
+	if *helpFlag || (inv.cmd != nil && !inv.cmd.noRegistry) {
+		err := selectRegistrySource()
+		if err != nil {
+			failf("Error: %v\n", err)
+			return exitError
+		}
 	}
 
-	// Handle help display.
+	// Handle help display, listing the components of the default ref.
 	if *helpFlag {
+		result.Command = "help"
 		fmt.Println("Fetching registry for help...")
 		registry, err := fetchRegistry(getDefaultRef())
 		if err != nil {
@@ -87,56 +162,64 @@ func main() {
 		} else {
 			showHelp(&registry, getDefaultRef())
 		}
-		return
+		return exitOK
 	}
 
-	args := flag.Args()
-
 	if len(args) == 0 {
-		fmt.Println("No command specified.")
+		failf("No command specified.\n")
 		showHelp(nil, getDefaultRef())
-		return
+		return exitUsage
 	}
 
-	commandArg := args[0]
-
-	// Route to appropriate command handler
-	switch {
-	case strings.HasPrefix(commandArg, "new"):
-		runNew(args, commandArg, *forceOverwrite, *moduleFlag)
-	case strings.HasPrefix(commandArg, "init"):
-		runInit(args, commandArg, *forceOverwrite)
-	case strings.HasPrefix(commandArg, "add"):
-		runAdd(args, commandArg, *forceOverwrite, *installedFlag)
-	case strings.HasPrefix(commandArg, "list"):
-		runList(args, commandArg)
-	case strings.HasPrefix(commandArg, "upgrade"):
-		runUpgrade(args, commandArg)
-	default:
-		fmt.Printf("Error: Unknown command '%s'\n", commandArg)
-		showHelp(nil, getDefaultRef())
+	return inv.cmd.run(inv)
+}
+
+// selectRegistrySource sets the registry source and the named registries from the flags and .templui.json.
+func selectRegistrySource() error {
+	namedRegistries = readConfiguredRegistries()
+	src, err := resolveRegistrySource(*registryFlag)
+	if err == nil {
+		src, err = withCache(src, *offlineFlag)
+	}
+	if err == nil {
+		src, err = withNamedRegistries(src, namedRegistries, *offlineFlag)
+	}
+	if err != nil {
+		return err
 	}
+	source = src
+	return nil
 }
 
 // showHelp displays the command usage instructions.
 func showHelp(registry *Registry, refUsedForHelp string) {
 	fmt.Println("templUI " + version + " - The UI Kit for templ" + "\n")
-	fmt.Println("Usage:")
-	fmt.Println("  templui new <project-name>              - Create a new templUI project")
-	fmt.Println("  templui --module <mod> new <name>       - Create project with custom module name")
-	fmt.Println("  templui init[@<ref>]                    - Initialize config and install utils from <ref>")
-	fmt.Println("  templui --force init[@<ref>]            - Force reinitialize and repair incomplete config")
-	fmt.Println("  templui add[@<ref>] <comp>...           - Add or update component(s) from specified <ref>")
-	fmt.Println("  templui add[@<ref>] \"*\"               - Add all components from specified <ref>")
-	fmt.Println("  templui --installed add[@<ref>]         - Update all currently installed components")
-	fmt.Println("  templui list[@<ref>]                    - List available components and utils from <ref>")
-	fmt.Println("  templui upgrade[@<ref>]                 - Upgrades the cli to <ref> or latest if no <ref> was given")
-	fmt.Println("  templui --version                       - Show installer version")
-	fmt.Println("  templui --help                          - Show this help message")
+	fmt.Println("Usage: templui <command>[@<ref>] [flags] [arguments]")
+	fmt.Println("\nCommands:")
+	for _, cmd := range commands {
+		if cmd.hidden {
+			continue
+		}
+		name := cmd.name
+		if cmd.ref {
+			name += "[@<ref>]"
+		}
+		fmt.Printf("  %-18s %s\n", name, cmd.summary)
+	}
+	fmt.Println("\nExamples:")
+	fmt.Println("  templui new myapp                          - Create a new project")
+	fmt.Println("  templui add button card                    - Add components from the default ref")
+	fmt.Println("  templui add @acme/datagrid                 - Add a component from a named registry in .templui.json")
+	fmt.Println("  templui add --ref v1.0.0 \"*\"               - Add all components from a ref (same as add@v1.0.0)")
+	fmt.Println("  templui add --installed --merge            - Update installed components, merging in your local changes")
+	fmt.Println("  templui add --dry-run button               - Show which files would change")
+	fmt.Println("  templui remove --prune carousel            - Also remove dependencies and utils no longer needed")
+	fmt.Println("  templui list --output json                 - Print a JSON result on stdout (works with every command)")
+	fmt.Println("  templui add --offline --registry ../templui button - Install from a local checkout, without network access")
 	fmt.Println("\n<ref> can be a branch name, tag name, or commit hash.")
 	fmt.Printf("If no <ref> is specified, components are fetched from the default ref (currently '%s').\n", refUsedForHelp)
-	fmt.Println("\nFlags:")
-	flag.PrintDefaults()
+	fmt.Println("Flags can also be given before the command (e.g. 'templui --force add button').")
+	fmt.Println("Run 'templui help <command>' to see the flags of a command.")
 
 	// Show component/util list only if --help was used and registry was fetched.
 	if registry != nil {
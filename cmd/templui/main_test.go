@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestRunCommandReadsRegistryOnlyWhenNeeded(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTree(t, ".", map[string]string{configFileName: `{"registry": "does-not-exist"}`})
+	useSource(t, nil)
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: []string{"version"}, want: exitOK},
+		{args: []string{"help", "add"}, want: exitOK},
+		{args: []string{"completion", "bash"}, want: exitOK},
+		{args: []string{"list"}, want: exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[0], func(t *testing.T) {
+			result = &commandResult{}
+			if err := flag.CommandLine.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if code := runCommand(); code != tt.want {
+				t.Errorf("runCommand() = %d, want %d (errors: %v)", code, tt.want, result.Errors)
+			}
+		})
+	}
+}
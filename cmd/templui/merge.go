@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// mergeLines performs a line-based three-way merge of ours and theirs, which both derive from base.
+// Changes made on only one side are applied; overlapping changes are written as conflict
+// blocks with markers. It returns the merged text and the number of conflicts.
+func mergeLines(base, ours, theirs string, oursLabel, baseLabel, theirsLabel string) (string, int) {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+	oursMatch := matchBaseLines(baseLines, oursLines)
+	theirsMatch := matchBaseLines(baseLines, theirsLines)
+
+	var out strings.Builder
+	conflicts := 0
+	b, o, t := 0, 0, 0
+	for b < len(baseLines) || o < len(oursLines) || t < len(theirsLines) {
+		// Find the next base line that is unchanged on both sides.
+		next := b
+		for next < len(baseLines) && (oursMatch[next] < 0 || theirsMatch[next] < 0) {
+			next++
+		}
+		if next == b && next < len(baseLines) && oursMatch[next] == o && theirsMatch[next] == t {
+			out.WriteString(baseLines[b])
+			b, o, t = b+1, o+1, t+1
+			continue
+		}
+
+		// Everything up to the next stable line was changed on at least one side.
+		oursEnd, theirsEnd := len(oursLines), len(theirsLines)
+		if next < len(baseLines) {
+			oursEnd, theirsEnd = oursMatch[next], theirsMatch[next]
+		}
+		baseChunk := baseLines[b:next]
+		oursChunk := oursLines[o:oursEnd]
+		theirsChunk := theirsLines[t:theirsEnd]
+
+		switch {
+		case slices.Equal(oursChunk, baseChunk):
+			out.WriteString(strings.Join(theirsChunk, ""))
+		case slices.Equal(theirsChunk, baseChunk), slices.Equal(oursChunk, theirsChunk):
+			out.WriteString(strings.Join(oursChunk, ""))
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + oursLabel + "\n")
+			writeLines(&out, oursChunk)
+			out.WriteString("||||||| " + baseLabel + "\n")
+			writeLines(&out, baseChunk)
+			out.WriteString("=======\n")
+			writeLines(&out, theirsChunk)
+			out.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+		b, o, t = next, oursEnd, theirsEnd
+	}
+	return out.String(), conflicts
+}
+
+// matchBaseLines maps each base line to the index of the same line in other, or -1 if it was changed.
+func matchBaseLines(base, other []string) []int {
+	match := make([]int, len(base))
+	b, o := 0, 0
+	for _, edit := range diffLines(base, other) {
+		switch edit.op {
+		case opEqual:
+			match[b] = o
+			b++
+			o++
+		case opDelete:
+			match[b] = -1
+			b++
+		case opInsert:
+			o++
+		}
+	}
+	return match
+}
+
+// writeLines writes the lines of a conflict block to out, terminating the last line if needed so markers start on their own line.
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteString("\n")
+	}
+}
+
+// renderMergeBase renders the content of a component file fetched at the installed ref
+// like an installation would have left it. It returns false if no base is available.
+func renderMergeBase(config Config, comp ComponentDef, repoFilePath, destPath, baseRef string, data []byte, err error) ([]byte, bool) {
+	if baseRef == "" {
+		fmt.Printf("      ⚠️  Cannot merge '%s': installed version is unknown.\n", destPath)
+		return nil, false
+	}
+	if err != nil {
+		fmt.Printf("      ⚠️  Cannot merge '%s': failed to fetch installed version '%s': %v\n", destPath, baseRef, err)
+		return nil, false
+	}
+	local, err := os.ReadFile(destPath)
+	if err != nil {
+		fmt.Printf("      ⚠️  Cannot merge '%s': %v\n", destPath, err)
+		return nil, false
+	}
+	base, err := renderPristineComponentFile(data, config, comp, baseRef, repoFilePath)
+	if err != nil {
+		fmt.Printf("      ⚠️  Cannot merge '%s': %v\n", destPath, err)
+		return nil, false
+	}
+	return alignVersionComment(base, local), true
+}
+
+// mergeComponentFile merges the local changes of an installed component file into its new version.
+// The pristine installed version is the base, the local file is "ours" and the new version is "theirs".
+func mergeComponentFile(destPath string, base, theirs []byte, baseRef, ref string, opts *installOptions) ([]byte, error) {
+	local, err := os.ReadFile(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' for merging: %w", destPath, err)
+	}
+
+	merged, conflicts := mergeLines(string(base), string(local), string(theirs), "local", "base ("+baseRef+")", ref)
+	if conflicts > 0 {
+		fmt.Printf("      ⚠️  Merged '%s' with %d conflict(s).\n", destPath, conflicts)
+		opts.conflicts = append(opts.conflicts, destPath)
+	} else {
+		fmt.Printf("      🔀 Merged local changes into '%s'.\n", destPath)
+		opts.merged = append(opts.merged, destPath)
+	}
+	return []byte(merged), nil
+}
+
+// printMergeSummary reports which files merged cleanly and which need attention.
+func printMergeSummary(opts *installOptions) {
+	if len(opts.merged) == 0 && len(opts.conflicts) == 0 {
+		return
+	}
+	fmt.Println("\n🔀 Merge results:")
+	for _, path := range opts.merged {
+		fmt.Printf("   ✅ %s (merged cleanly)\n", path)
+	}
+	for _, path := range opts.conflicts {
+		fmt.Printf("   ⚠️  %s (conflicts, resolve the <<<<<<< markers)\n", path)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import "testing"
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		wantConflicts      int
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "changed in ours only",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "changed in theirs only",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nC\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "separate changes on both sides",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "identical changes on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nx\ny\nc\n",
+			theirs: "a\nx\ny\nc\n",
+			want:   "a\nx\ny\nc\n",
+		},
+		{
+			name:   "deleted in ours, unchanged in theirs",
+			base:   "a\nb\nc\n",
+			ours:   "a\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nc\n",
+		},
+		{
+			name:          "overlapping changes",
+			base:          "a\nb\nc\n",
+			ours:          "a\nours\nc\n",
+			theirs:        "a\ntheirs\nc\n",
+			want:          "a\n<<<<<<< local\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> new\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "deleted in ours, changed in theirs",
+			base:          "a\nb\nc\n",
+			ours:          "a\nc\n",
+			theirs:        "a\nB\nc\n",
+			want:          "a\n<<<<<<< local\n||||||| base\nb\n=======\nB\n>>>>>>> new\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "insertions at the same spot",
+			base:          "a\nc\n",
+			ours:          "a\nx\nc\n",
+			theirs:        "a\ny\nc\n",
+			want:          "a\n<<<<<<< local\nx\n||||||| base\n=======\ny\n>>>>>>> new\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:   "identical insertions at the same spot",
+			base:   "a\nc\n",
+			ours:   "a\nx\nc\n",
+			theirs: "a\nx\nc\n",
+			want:   "a\nx\nc\n",
+		},
+		{
+			name:   "insertions at the end",
+			base:   "a\n",
+			ours:   "a\n",
+			theirs: "a\nb\n",
+			want:   "a\nb\n",
+		},
+		{
+			name:          "two conflicts",
+			base:          "a\nb\nc\nd\ne\n",
+			ours:          "A1\nb\nc\nd\nE1\n",
+			theirs:        "A2\nb\nc\nd\nE2\n",
+			want:          "<<<<<<< local\nA1\n||||||| base\na\n=======\nA2\n>>>>>>> new\nb\nc\nd\n<<<<<<< local\nE1\n||||||| base\ne\n=======\nE2\n>>>>>>> new\n",
+			wantConflicts: 2,
+		},
+		{
+			name:   "missing trailing newline kept",
+			base:   "a\nb",
+			ours:   "A\nb",
+			theirs: "a\nb",
+			want:   "A\nb",
+		},
+		{
+			name:   "line appended after missing trailing newline",
+			base:   "a\nb",
+			ours:   "a\nb",
+			theirs: "a\nb\nc",
+			want:   "a\nb\nc",
+		},
+		{
+			name:          "conflict without trailing newline",
+			base:          "a\nb",
+			ours:          "a\nours",
+			theirs:        "a\ntheirs",
+			want:          "a\n<<<<<<< local\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> new\n",
+			wantConflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeLines(tt.base, tt.ours, tt.theirs, "local", "base", "new")
+			if got != tt.want {
+				t.Errorf("mergeLines() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("got %d conflicts, want %d", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
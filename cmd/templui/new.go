@@ -32,31 +32,16 @@ type TemplateData struct {
 }
 
 // runNew handles the 'new' command logic.
-func runNew(args []string, commandArg string, force bool, moduleFlag string) {
+func runNew(args []string, ref string, force bool, moduleFlag string, dryRun bool) {
 	targetRef := getDefaultRef()
-
-	// Parse optional @ref from the command argument.
-	baseCommand := commandArg
-	if strings.Contains(commandArg, "@") {
-		parts := strings.SplitN(commandArg, "@", 2)
-		if len(parts) == 2 && parts[0] == "new" && parts[1] != "" {
-			targetRef = parts[1]
-			baseCommand = "new"
-			fmt.Printf("Using specified ref: %s\n", targetRef)
-		} else {
-			fmt.Printf("Error: Invalid format '%s'. Use 'new' or 'new@<ref>'.\n", commandArg)
-			return
-		}
-	} else if commandArg != "new" {
-		fmt.Printf("Error: Unknown command '%s'. Did you mean 'new'?\n", commandArg)
-		showHelp(nil, getDefaultRef())
-		return
+	if ref != "" {
+		targetRef = ref
+		fmt.Printf("Using specified ref: %s\n", targetRef)
 	}
-	_ = baseCommand // Avoid unused variable warning
 
 	// Ensure project name is provided
-	if len(args) < 2 {
-		fmt.Println("Error: No project name specified.")
+	if len(args) == 0 {
+		failf("Error: No project name specified.\n")
 		fmt.Println("Usage: templui new <name>")
 		fmt.Println("       templui new myapp")
 		fmt.Println("       templui new github.com/user/myapp")
@@ -66,7 +51,7 @@ func runNew(args []string, commandArg string, force bool, moduleFlag string) {
 	// Module name is exactly what user provided (like go mod init)
 	moduleName := moduleFlag
 	if moduleName == "" {
-		moduleName = args[1]
+		moduleName = args[0]
 	}
 
 	// Directory name is the last part after slash
@@ -78,7 +63,7 @@ func runNew(args []string, commandArg string, force bool, moduleFlag string) {
 	// Check if project directory already exists
 	if _, err := os.Stat(dirName); err == nil {
 		if !force {
-			fmt.Printf("Error: Directory '%s' already exists. Use -f to overwrite.\n", dirName)
+			failf("Error: Directory '%s' already exists. Use -f to overwrite.\n", dirName)
 			return
 		}
 		fmt.Printf("Warning: Directory '%s' exists. Overwriting...\n", dirName)
@@ -94,14 +79,22 @@ func runNew(args []string, commandArg string, force bool, moduleFlag string) {
 	// Load template config
 	templateConfig, err := loadTemplateConfig()
 	if err != nil {
-		fmt.Printf("Error loading template config: %v\n", err)
+		failf("Error loading template config: %v\n", err)
+		return
+	}
+
+	result.Ref = targetRef
+	result.Project = dirName
+
+	if dryRun {
+		planNew(dirName, moduleName, targetRef, templateConfig)
 		return
 	}
 
 	// Create project directory
 	err = os.MkdirAll(dirName, 0755)
 	if err != nil {
-		fmt.Printf("Error creating project directory: %v\n", err)
+		failf("Error creating project directory: %v\n", err)
 		return
 	}
 
@@ -112,7 +105,7 @@ func runNew(args []string, commandArg string, force bool, moduleFlag string) {
 
 	err = copyTemplateFiles(dirName, templateData)
 	if err != nil {
-		fmt.Printf("Error copying template files: %v\n", err)
+		failf("Error copying template files: %v\n", err)
 		return
 	}
 	fmt.Println("✅ Created project structure")
@@ -129,12 +122,12 @@ func runNew(args []string, commandArg string, force bool, moduleFlag string) {
 	configPath := filepath.Join(dirName, configFileName)
 	configData, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		fmt.Printf("Error creating config: %v\n", err)
+		failf("Error creating config: %v\n", err)
 		return
 	}
 	err = os.WriteFile(configPath, configData, 0644)
 	if err != nil {
-		fmt.Printf("Error writing config file: %v\n", err)
+		failf("Error writing config file: %v\n", err)
 		return
 	}
 	fmt.Println("✅ Created .templui.json")
@@ -143,65 +136,46 @@ func runNew(args []string, commandArg string, force bool, moduleFlag string) {
 	originalDir, _ := os.Getwd()
 	err = os.Chdir(dirName)
 	if err != nil {
-		fmt.Printf("Error changing to project directory: %v\n", err)
+		failf("Error changing to project directory: %v\n", err)
 		return
 	}
 	defer os.Chdir(originalDir)
 
-	// Install utils first
-	fmt.Println("\n📦 Installing utils...")
+	// Record everything installed into the new project
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return
+	}
+
+	opts := &installOptions{force: true, lock: lock}
+
 	registry, err := fetchRegistry(targetRef)
+	if err == nil {
+		err = checkCLIVersion(registry)
+	}
 	if err != nil {
 		fmt.Printf("Warning: Could not fetch registry: %v\n", err)
 	} else {
-		if len(registry.Utils) > 0 {
-			allUtilPaths := []string{}
-			for _, utilDef := range registry.Utils {
-				allUtilPaths = append(allUtilPaths, utilDef.Path)
-			}
-			err = installUtils(config, allUtilPaths, targetRef, true)
-			if err != nil {
-				fmt.Printf("Warning: Error installing utils: %v\n", err)
-			}
+		opts.hashes = registry.FileHashes()
+		comps, allUtilPaths, requiredUtils, err := templateInstallSet(registry, templateConfig, targetRef)
+		if err == nil {
+			fmt.Println("\n📦 Installing components and utils...")
+			err = install(config, targetRef, comps, append(allUtilPaths, requiredUtils...), opts)
 		}
-	}
-
-	// Install components
-	if len(templateConfig.Components) > 0 {
-		fmt.Println("\n📦 Installing components...")
-
-		// Build component map
-		componentMap := make(map[string]ComponentDef)
-		for _, comp := range registry.Components {
-			componentMap[comp.Name] = comp
+		if err != nil {
+			failEach("❌ Error installing %v\n", err)
 		}
-
-		installedComponents := make(map[string]bool)
-		requiredUtils := make(map[string]bool)
-
+		lock.markUtilsExplicit(allUtilPaths)
 		for _, compName := range templateConfig.Components {
-			compDef, exists := componentMap[compName]
-			if !exists {
-				fmt.Printf("   ⚠️  Component '%s' not found in registry\n", compName)
-				continue
-			}
-
-			err = installComponent(config, compDef, componentMap, targetRef, installedComponents, requiredUtils, true)
-			if err != nil {
-				fmt.Printf("   ⚠️  Error installing %s: %v\n", compName, err)
-			} else {
-				fmt.Printf("   ✅ %s\n", compName)
-			}
+			lock.markExplicit(compName)
 		}
+	}
 
-		// Install any additional required utils from components
-		if len(requiredUtils) > 0 {
-			utilsToInstall := []string{}
-			for utilPath := range requiredUtils {
-				utilsToInstall = append(utilsToInstall, utilPath)
-			}
-			installUtils(config, utilsToInstall, targetRef, true)
-		}
+	recordInstall(opts)
+	err = saveLockfile(lock)
+	if err != nil {
+		fmt.Printf("Warning: Could not write %s: %v\n", lockFileName, err)
 	}
 
 	// Generate templ files (using global templ binary to avoid go.sum chicken-egg problem)
@@ -248,6 +222,99 @@ func loadTemplateConfig() (TemplateConfig, error) {
 	return config, nil
 }
 
+// templateInstallSet returns the components of the template with their dependencies, all utils
+// of the registry and any further utils required by the components.
+func templateInstallSet(registry Registry, templateConfig TemplateConfig, ref string) ([]ComponentDef, []string, []string, error) {
+	componentMap := make(map[string]ComponentDef)
+	for _, comp := range registry.Components {
+		componentMap[comp.Name] = comp
+	}
+	for _, compName := range templateConfig.Components {
+		if _, exists := componentMap[compName]; !exists {
+			fmt.Printf("   ⚠️  Component '%s' not found in registry\n", compName)
+		}
+	}
+	comps, requiredUtils, err := resolveComponents(templateConfig.Components, componentMap, ref)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	allUtilPaths := []string{}
+	for _, utilDef := range registry.Utils {
+		allUtilPaths = append(allUtilPaths, utilDef.Path)
+	}
+	return comps, allUtilPaths, requiredUtils, nil
+}
+
+// templateDestPath returns where a quickstart template file is written in the project directory.
+func templateDestPath(destDir, path string) string {
+	relPath := strings.TrimPrefix(path, "quickstart/")
+	destPath := filepath.Join(destDir, relPath)
+
+	// Handle .tmpl files - strip the .tmpl extension
+	return strings.TrimSuffix(destPath, ".tmpl")
+}
+
+// planNew prints what 'new' would create, without writing anything or running external tools.
+func planNew(dirName, moduleName, ref string, templateConfig TemplateConfig) {
+	opts := &installOptions{force: true, dryRun: true}
+
+	err := fs.WalkDir(templates.QuickstartFS, "quickstart", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path == "quickstart/template.json" {
+			return nil
+		}
+		destPath := templateDestPath(dirName, path)
+		action := actionCreate
+		if _, err := os.Stat(destPath); err == nil {
+			action = actionOverwrite
+		}
+		opts.record(fileAction{Kind: "template", Name: "quickstart", Path: destPath, Action: action})
+		return nil
+	})
+	if err != nil {
+		failf("Error reading template files: %v\n", err)
+		return
+	}
+
+	configPath := filepath.Join(dirName, configFileName)
+	configAction := actionCreate
+	if _, err := os.Stat(configPath); err == nil {
+		configAction = actionOverwrite
+	}
+	opts.record(fileAction{Kind: "config", Name: configFileName, Path: configPath, Action: configAction})
+
+	// Plan against the project directory, as paths are checked from the current directory.
+	config := Config{
+		ComponentsDir: filepath.Join(dirName, templateConfig.DefaultConfig.ComponentsDir),
+		UtilsDir:      filepath.Join(dirName, templateConfig.DefaultConfig.UtilsDir),
+		ModuleName:    moduleName,
+		JSDir:         filepath.Join(dirName, templateConfig.DefaultConfig.JSDir),
+		JSPublicPath:  templateConfig.DefaultConfig.JSPublicPath,
+	}
+
+	registry, err := fetchRegistry(ref)
+	if err == nil {
+		err = checkCLIVersion(registry)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Could not fetch registry: %v\n", err)
+	} else {
+		comps, allUtilPaths, requiredUtils, err := templateInstallSet(registry, templateConfig, ref)
+		if err != nil {
+			failf("❌ Error resolving dependencies: %v\n", err)
+		} else {
+			install(config, ref, comps, append(allUtilPaths, requiredUtils...), opts)
+		}
+	}
+
+	recordInstall(opts)
+	printInstallPlan(opts.actions)
+	fmt.Printf("\nWould then run 'templ generate' and 'go mod tidy' in '%s'.\n", dirName)
+}
+
 // copyTemplateFiles copies and processes template files to the destination
 func copyTemplateFiles(destDir string, data TemplateData) error {
 	return fs.WalkDir(templates.QuickstartFS, "quickstart", func(path string, d fs.DirEntry, err error) error {
@@ -260,14 +327,7 @@ func copyTemplateFiles(destDir string, data TemplateData) error {
 			return nil
 		}
 
-		// Calculate destination path
-		relPath := strings.TrimPrefix(path, "quickstart/")
-		destPath := filepath.Join(destDir, relPath)
-
-		// Handle .tmpl files - strip the .tmpl extension
-		if strings.HasSuffix(destPath, ".tmpl") {
-			destPath = strings.TrimSuffix(destPath, ".tmpl")
-		}
+		destPath := templateDestPath(destDir, path)
 
 		if d.IsDir() {
 			return os.MkdirAll(destPath, 0755)
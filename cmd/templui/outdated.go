@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes of the 'outdated' command, following diff.
+const (
+	outdatedExitCurrent  = 0
+	outdatedExitOutdated = 1
+	outdatedExitError    = 2
+)
+
+// outdatedEntry reports what would change in an installed component or util when moving to the target ref.
+type outdatedEntry struct {
+	Kind         string   `json:"kind"`                   // "component" or "util"
+	Name         string   `json:"name"`                   // Component name or util path
+	Installed    string   `json:"installed"`              // Ref the component or util was installed from
+	Target       string   `json:"target"`                 // Ref compared against
+	Changed      []string `json:"changed,omitempty"`      // Repository files whose content differs
+	Added        []string `json:"added,omitempty"`        // Repository files new in the target ref
+	Deleted      []string `json:"deleted,omitempty"`      // Repository files no longer in the target ref
+	Dependencies []string `json:"dependencies,omitempty"` // Dependencies new in the target ref
+	Removed      bool     `json:"removed,omitempty"`      // Whether it no longer exists in the target ref
+}
+
+// runOutdated handles the 'outdated' command logic and returns the exit code.
+func runOutdated(args []string, targetRef string) int {
+	config, err := loadConfig()
+	if err != nil {
+		failf("Error loading config: %v\n", err)
+		return outdatedExitError
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return outdatedExitError
+	}
+
+	if targetRef == "" {
+		targetRef = latestRef()
+	}
+	result.Ref = targetRef
+
+	// Without explicit components, check everything that is installed, including utils.
+	componentNames := args
+	checkUtils := len(componentNames) == 0
+	if checkUtils {
+		componentNames, err = getInstalledComponentNames(config, lock)
+		if err != nil {
+			failf("Error detecting installed components: %v\n", err)
+			return outdatedExitError
+		}
+	}
+
+	fmt.Printf("Comparing installed components with ref '%s'...\n", targetRef)
+	target, err := fetchRegistry(targetRef)
+	if err != nil {
+		failf("Error fetching registry for ref '%s': %v\n", targetRef, err)
+		return outdatedExitError
+	}
+	registryAt := registryLookup()
+
+	exitCode := outdatedExitCurrent
+	var entries []outdatedEntry
+	for _, componentName := range componentNames {
+		compTarget, compTargetRef, err := targetRegistry(componentName, target, targetRef, registryAt)
+		var entry *outdatedEntry
+		if err == nil {
+			entry, err = outdatedComponent(config, lock, componentName, compTarget, compTargetRef, registryAt)
+		}
+		if err != nil {
+			failf("Error checking component '%s': %v\n", componentName, err)
+			exitCode = outdatedExitError
+			continue
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	if checkUtils {
+		utils, err := installedUtils(config, lock)
+		if err != nil {
+			failf("Error detecting installed utils: %v\n", err)
+			return outdatedExitError
+		}
+		for _, util := range utils {
+			utilTarget, utilTargetRef, err := targetRegistry(util.repoPath, target, targetRef, registryAt)
+			var entry *outdatedEntry
+			if err == nil {
+				entry, err = outdatedUtil(util, utilTarget, utilTargetRef, registryAt)
+			}
+			if err != nil {
+				failf("Error checking util '%s': %v\n", util.repoPath, err)
+				exitCode = outdatedExitError
+				continue
+			}
+			if entry != nil {
+				entries = append(entries, *entry)
+			}
+		}
+	}
+
+	result.Outdated = entries
+	printOutdated(entries, targetRef)
+	if len(entries) > 0 && exitCode == outdatedExitCurrent {
+		exitCode = outdatedExitOutdated
+	}
+	return exitCode
+}
+
+// latestRef returns the tag of the latest release, falling back to the default ref
+// if the registry source has no releases or can't be queried.
+func latestRef() string {
+	tag, err := source.latestRelease()
+	if err != nil {
+		fmt.Printf("⚠️  Could not determine the latest release, using '%s': %v\n", getDefaultRef(), err)
+		return getDefaultRef()
+	}
+	if tag == "" {
+		return getDefaultRef()
+	}
+	fmt.Printf("Latest release: %s\n", tag)
+	return tag
+}
+
+// targetRegistry returns the registry and ref a component or util is compared against: the target registry
+// for the default registry, or the configured ref of its named registry.
+func targetRegistry(name string, target Registry, targetRef string, registryAt func(name, ref string) *Registry) (Registry, string, error) {
+	if scope, _ := splitScope(name); scope == "" {
+		return target, targetRef, nil
+	}
+	ref := registryRef(name, targetRef)
+	registry := registryAt(name, ref)
+	if registry == nil {
+		return Registry{}, ref, fmt.Errorf("registry for ref '%s' is unavailable", ref)
+	}
+	return *registry, ref, nil
+}
+
+// outdatedComponent compares an installed component with its definition in the target registry.
+// It returns nil if the component is up to date.
+func outdatedComponent(config Config, lock *Lockfile, componentName string, target Registry, targetRef string, registryAt func(name, ref string) *Registry) (*outdatedEntry, error) {
+	installedRef, err := installedComponentRef(config, lock, componentName)
+	if err != nil {
+		return nil, err
+	}
+	if installedRef == targetRef {
+		return nil, nil
+	}
+	entry := &outdatedEntry{Kind: "component", Name: componentName, Installed: installedRef, Target: targetRef}
+
+	newComp, ok := findComponent(target, componentName)
+	if !ok {
+		entry.Removed = true
+		return entry, nil
+	}
+	installed := registryAt(componentName, installedRef)
+	if installed == nil {
+		return nil, fmt.Errorf("registry for installed ref '%s' is unavailable", installedRef)
+	}
+	oldComp, ok := findComponent(*installed, componentName)
+	if !ok {
+		return nil, fmt.Errorf("component '%s' not found in registry for installed ref '%s'", componentName, installedRef)
+	}
+
+	oldFiles, newFiles := componentRepoFiles(oldComp), componentRepoFiles(newComp)
+	for _, repoPath := range newFiles {
+		if !slices.Contains(oldFiles, repoPath) {
+			entry.Added = append(entry.Added, repoPath)
+			continue
+		}
+		changed, err := upstreamChanged(repoPath, installedRef, targetRef, oldComp.Hashes, newComp.Hashes)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			entry.Changed = append(entry.Changed, repoPath)
+		}
+	}
+	for _, repoPath := range oldFiles {
+		if !slices.Contains(newFiles, repoPath) {
+			entry.Deleted = append(entry.Deleted, repoPath)
+		}
+	}
+	for _, dep := range newComp.Dependencies {
+		if !slices.Contains(oldComp.Dependencies, dep) {
+			entry.Dependencies = append(entry.Dependencies, dep)
+		}
+	}
+
+	if len(entry.Changed) == 0 && len(entry.Added) == 0 && len(entry.Deleted) == 0 && len(entry.Dependencies) == 0 {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// outdatedUtil compares an installed util with the target registry. It returns nil if the util is up to date.
+func outdatedUtil(util installedUtil, target Registry, targetRef string, registryAt func(name, ref string) *Registry) (*outdatedEntry, error) {
+	installedRef := ""
+	if util.locked != nil {
+		installedRef = util.locked.Ref
+	} else {
+		ref, err := readFileVersion(filepath.FromSlash(util.path))
+		if err != nil {
+			return nil, err
+		}
+		installedRef = ref
+	}
+	if installedRef == targetRef {
+		return nil, nil
+	}
+	entry := &outdatedEntry{Kind: "util", Name: util.repoPath, Installed: installedRef, Target: targetRef}
+
+	if !slices.ContainsFunc(target.Utils, func(u UtilDef) bool { return u.Path == util.repoPath }) {
+		entry.Removed = true
+		return entry, nil
+	}
+	installed := registryAt(util.repoPath, installedRef)
+	if installed == nil {
+		return nil, fmt.Errorf("registry for installed ref '%s' is unavailable", installedRef)
+	}
+	changed, err := upstreamChanged(util.repoPath, installedRef, targetRef, installed.FileHashes(), target.FileHashes())
+	if err != nil || !changed {
+		return nil, err
+	}
+	entry.Changed = []string{util.repoPath}
+	return entry, nil
+}
+
+// componentRepoFiles returns the repository paths of a component's files, including its JavaScript file.
+func componentRepoFiles(comp ComponentDef) []string {
+	files := slices.Clone(comp.Files)
+	if comp.HasJS {
+		files = append(files, componentJSRepoPath(comp.Name))
+	}
+	return files
+}
+
+// upstreamChanged reports whether a repository file differs between two refs. The published hashes
+// are compared if both registries have them, otherwise both versions are fetched.
+func upstreamChanged(repoPath, fromRef, toRef string, fromHashes, toHashes map[string]string) (bool, error) {
+	from, to := fromHashes[repoPath], toHashes[repoPath]
+	if from != "" && to != "" {
+		return from != to, nil
+	}
+	fromData, err := fetchVerified(fromRef, repoPath, fromHashes)
+	if err != nil {
+		return false, err
+	}
+	toData, err := fetchVerified(toRef, repoPath, toHashes)
+	if err != nil {
+		return false, err
+	}
+	return hashContent(fromData) != hashContent(toData), nil
+}
+
+// printOutdated prints the outdated components and utils as a table.
+func printOutdated(entries []outdatedEntry, targetRef string) {
+	if len(entries) == 0 {
+		fmt.Printf("✅ Everything is up to date with ref '%s'.\n", targetRef)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tINSTALLED\tTARGET\tCHANGES")
+	hasUtils := false
+	for _, e := range entries {
+		installed := e.Installed
+		if installed == "" {
+			installed = "?"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Kind, e.Name, installed, e.Target, e.summary())
+		hasUtils = hasUtils || e.Kind == "util"
+	}
+	w.Flush()
+
+	fmt.Printf("\n💡 Run 'templui add@%s --installed' to update the installed components.\n", targetRef)
+	if hasUtils {
+		fmt.Printf("💡 Run 'templui upgrade@%s' to update the utils.\n", targetRef)
+	}
+}
+
+// summary describes the changes of an outdated entry in a few words.
+func (e outdatedEntry) summary() string {
+	if e.Removed {
+		return "removed in " + e.Target
+	}
+	var parts []string
+	if len(e.Changed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d file(s) changed", len(e.Changed)))
+	}
+	if len(e.Added) > 0 {
+		parts = append(parts, fmt.Sprintf("%d file(s) added", len(e.Added)))
+	}
+	if len(e.Deleted) > 0 {
+		parts = append(parts, fmt.Sprintf("%d file(s) deleted", len(e.Deleted)))
+	}
+	if len(e.Dependencies) > 0 {
+		parts = append(parts, "new dependencies: "+strings.Join(e.Dependencies, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Output formats accepted by --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// commandResult is the structured result of a command, printed with --output json.
+type commandResult struct {
+	Command    string          `json:"command"`
+	Ref        string          `json:"ref,omitempty"`
+	Success    bool            `json:"success"`
+	Errors     []string        `json:"errors,omitempty"`
+	Version    string          `json:"version,omitempty"`    // version
+	Project    string          `json:"project,omitempty"`    // new: created project directory
+	Components []ComponentDef  `json:"components,omitempty"` // list
+	Utils      []UtilDef       `json:"utils,omitempty"`      // list
+	Actions    []fileAction    `json:"actions,omitempty"`    // add, init, new, upgrade: what happened to each file
+	Merged     []string        `json:"merged,omitempty"`     // add --merge: files merged cleanly
+	Conflicts  []string        `json:"conflicts,omitempty"`  // add --merge: files written with conflict markers
+	Removed    []string        `json:"removed,omitempty"`    // remove: removed components
+	Pruned     []string        `json:"pruned,omitempty"`     // remove --prune: pruned components and util paths
+	Diffs      []fileDiff      `json:"diffs,omitempty"`      // diff: files that differ
+	Cached     []cacheEntry    `json:"cached,omitempty"`     // cache list: cached files
+	Verified   []verifyResult  `json:"verified,omitempty"`   // verify: state of each installed file
+	Status     []statusEntry   `json:"status,omitempty"`     // status: state of each installed component and util
+	Outdated   []outdatedEntry `json:"outdated,omitempty"`   // outdated: what would change with the target ref
+	Info       *componentInfo  `json:"info,omitempty"`       // info: full metadata of a component
+	Why        *whyResult      `json:"why,omitempty"`        // why: dependency paths to a component
+	Graph      string          `json:"graph,omitempty"`      // graph: rendered dependency graph
+	Checks     []doctorCheck   `json:"checks,omitempty"`     // doctor: outcome of each check
+	Changes    []string        `json:"changes,omitempty"`    // registry build: fields that differ from the components
+}
+
+// fileDiff is the unified diff of a single installed file against the registry.
+type fileDiff struct {
+	Component string `json:"component"`
+	Path      string `json:"path"`
+	Ref       string `json:"ref"`
+	Diff      string `json:"diff"`
+}
+
+var (
+	// result collects the outcome of the command being run.
+	result = &commandResult{}
+
+	// resultOutput receives the JSON result. In JSON mode, os.Stdout is pointed at
+	// stderr so that progress messages don't mix with the result.
+	resultOutput = os.Stdout
+)
+
+// setOutputFormat prepares stdout for the given --output format.
+func setOutputFormat(format string) error {
+	switch format {
+	case outputText:
+		return nil
+	case outputJSON:
+		resultOutput = os.Stdout
+		os.Stdout = os.Stderr
+		return nil
+	default:
+		return fmt.Errorf("unknown output format '%s'. Use '%s' or '%s'", format, outputText, outputJSON)
+	}
+}
+
+// failf prints an error message and records it as a failure of the command.
+func failf(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Print(message)
+	result.Errors = append(result.Errors, strings.TrimSpace(strings.TrimPrefix(message, "❌ ")))
+}
+
+// failEach records each of the errors joined into err as a separate failure.
+func failEach(format string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			failf(format, e)
+		}
+		return
+	}
+	failf(format, err)
+}
+
+// recordInstall adds the file actions and merge results of an installation to the command result.
+func recordInstall(opts *installOptions) {
+	result.Actions = append(result.Actions, opts.actions...)
+	result.Merged = append(result.Merged, opts.merged...)
+	result.Conflicts = append(result.Conflicts, opts.conflicts...)
+}
+
+// finishCommand prints the JSON result if requested and returns the process exit code.
+// Commands that recorded an error exit with 1 unless they chose another non-zero code.
+func finishCommand(format string, exitCode int) int {
+	if exitCode == 0 && len(result.Errors) > 0 {
+		exitCode = 1
+	}
+	result.Success = len(result.Errors) == 0
+
+	if format == outputJSON {
+		encoder := json.NewEncoder(resultOutput)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON output: %v\n", err)
+			return 1
+		}
+	}
+	return exitCode
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// planSections lists the sections of a dry-run plan in display order.
+var planSections = []struct {
+	action string
+	title  string
+	symbol string
+}{
+	{actionCreate, "Would create", "+"},
+	{actionOverwrite, "Would overwrite", "~"},
+	{actionMerge, "Would merge local changes into", "~"},
+	{actionPrompt, "Would ask before overwriting", "?"},
+	{actionSkip, "Would skip (already at requested ref)", "="},
+	{actionAddScript, "Would add Script() template to", "+"},
+}
+
+// printInstallPlan prints the file actions collected during a dry run.
+func printInstallPlan(actions []fileAction) {
+	fmt.Print("\n" + strings.Repeat("─", 50) + "\n")
+	fmt.Printf("📋 DRY RUN PLAN (nothing was written)\n")
+	fmt.Printf("%s\n", strings.Repeat("─", 50))
+
+	if len(actions) == 0 {
+		fmt.Println("\nNothing to do.")
+		return
+	}
+
+	for _, section := range planSections {
+		var lines []string
+		for _, action := range actions {
+			if action.Action != section.action {
+				continue
+			}
+			line := fmt.Sprintf("   %s %-45s [%s %s]", section.symbol, action.Path, action.Kind, action.Name)
+			if action.ExistingRef != "" && action.ExistingRef != action.Ref {
+				line += fmt.Sprintf(" %s → %s", action.ExistingRef, action.Ref)
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", section.title)
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultNamedRegistryRef is the ref named registries are installed from if none is configured.
+const defaultNamedRegistryRef = "main"
+
+// registryNameRegex matches the names of named registries, e.g., "@acme". The name without "@"
+// becomes a directory and package name, so it must be a Go identifier.
+var registryNameRegex = regexp.MustCompile(`^@[a-z][a-z0-9_]*$`)
+
+// namedRegistries are the named registries configured in .templui.json (selected in main).
+var namedRegistries map[string]NamedRegistry
+
+// splitScope splits a component name or repository path of a named registry, like "@acme/datagrid",
+// into the registry name and the rest. Names of the default registry are returned with an empty registry name.
+func splitScope(name string) (string, string) {
+	if !strings.HasPrefix(name, "@") {
+		return "", name
+	}
+	scope, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return "", name
+	}
+	return scope, rest
+}
+
+// qualifiedName prefixes a name with the name of its registry, unless it is from the default registry.
+func qualifiedName(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "/" + name
+}
+
+// scopeDir returns the directory the components and utils of a named registry are installed in,
+// below the configured directories ("acme" for "@acme", "" for the default registry).
+func scopeDir(scope string) string {
+	return strings.TrimPrefix(scope, "@")
+}
+
+// registryRef returns the ref a component or file is installed from: the configured ref of its
+// named registry, or ref if it comes from the default registry.
+func registryRef(name, ref string) string {
+	scope, _ := splitScope(name)
+	if scope == "" {
+		return ref
+	}
+	return namedRegistryRef(scope)
+}
+
+// namedRegistryRef returns the configured ref of a named registry.
+func namedRegistryRef(scope string) string {
+	if configured := namedRegistries[scope].Ref; configured != "" {
+		return configured
+	}
+	return defaultNamedRegistryRef
+}
+
+// registryImportPrefix returns the import path prefix of the internal packages of a registry's repository.
+func registryImportPrefix(scope string) string {
+	prefix := namedRegistries[scope].ImportPrefix
+	if scope == "" || prefix == "" {
+		return internalImportPrefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/"
+}
+
+// componentRepoDir returns the repository directory of a component, e.g., "internal/components/button/"
+// or "@acme/internal/components/datagrid/".
+func componentRepoDir(name string) string {
+	scope, name := splitScope(name)
+	return qualifiedName(scope, repoComponentBasePath+name+"/")
+}
+
+// componentJSFile returns the path of a component's JavaScript file relative to the JavaScript directory,
+// e.g., "button.min.js" or "acme/datagrid.min.js".
+func componentJSFile(name string) string {
+	scope, name := splitScope(name)
+	if scope == "" {
+		return name + ".min.js"
+	}
+	return scopeDir(scope) + "/" + name + ".min.js"
+}
+
+// componentJSRepoPath returns the repository path of a component's JavaScript file.
+func componentJSRepoPath(name string) string {
+	_, baseName := splitScope(name)
+	return componentRepoDir(name) + baseName + ".min.js"
+}
+
+// componentJSPath returns where the JavaScript file of a component is installed.
+func componentJSPath(config Config, name string) string {
+	return filepath.Join(config.JSDir, filepath.FromSlash(componentJSFile(name)))
+}
+
+// utilDestPath maps a util path from the registry to its destination in the project, preserving
+// subdirectory structure. It returns false if the path is not inside the utils directory of the repository,
+// in which case the util is placed directly in the utils directory of its registry.
+func utilDestPath(config Config, repoUtilPath string) (string, bool) {
+	scope, repoPath := splitScope(repoUtilPath)
+	if relativePath, ok := strings.CutPrefix(repoPath, repoUtilBasePath); ok {
+		return filepath.Join(config.utilsDir(scope), filepath.FromSlash(relativePath)), true
+	}
+	return filepath.Join(config.utilsDir(scope), filepath.Base(repoPath)), false
+}
+
+// utilRepoPath maps the path of an installed util, relative to the utils directory, back to its repository path.
+func utilRepoPath(relativePath string) string {
+	first, rest, ok := strings.Cut(relativePath, "/")
+	if _, named := namedRegistries["@"+first]; ok && named {
+		return qualifiedName("@"+first, repoUtilBasePath+rest)
+	}
+	return repoUtilBasePath + relativePath
+}
+
+// utilPackageName returns the package name of the installed utils of a registry: the name of their directory.
+func utilPackageName(config Config, repoUtilPath string) string {
+	scope, _ := splitScope(repoUtilPath)
+	return filepath.Base(config.utilsDir(scope))
+}
+
+// scopedSource routes the repository paths of named registries ("@acme/...") to their sources
+// and everything else to the default registry source.
+type scopedSource struct {
+	defaultSource registrySource
+	scopes        map[string]registrySource
+}
+
+// sourceOf returns the source of a repository path and the path within that source.
+func (s *scopedSource) sourceOf(repoPath string) (registrySource, string, error) {
+	scope, rest := splitScope(repoPath)
+	if scope == "" {
+		return s.defaultSource, repoPath, nil
+	}
+	src, ok := s.scopes[scope]
+	if !ok {
+		return nil, "", fmt.Errorf("registry '%s' is not configured in %s", scope, configFileName)
+	}
+	return src, rest, nil
+}
+
+func (s *scopedSource) location(ref, repoPath string) string {
+	src, rest, err := s.sourceOf(repoPath)
+	if err != nil {
+		return repoPath
+	}
+	return src.location(ref, rest)
+}
+
+func (s *scopedSource) fetch(ref, repoPath string) ([]byte, error) {
+	src, rest, err := s.sourceOf(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return src.fetch(ref, rest)
+}
+
+func (s *scopedSource) commit(ref string) (string, error) {
+	return s.defaultSource.commit(ref)
+}
+
+func (s *scopedSource) latestRelease() (string, error) {
+	return s.defaultSource.latestRelease()
+}
+
+// sourceFor returns the source of a registry ("" for the default registry).
+func sourceFor(scope string) registrySource {
+	scoped, ok := source.(*scopedSource)
+	if !ok {
+		return source
+	}
+	if src, ok := scoped.scopes[scope]; ok {
+		return src
+	}
+	return scoped.defaultSource
+}
+
+// newNamedRegistrySource creates the source of a named registry, sending its configured headers.
+func newNamedRegistrySource(registry NamedRegistry) (registrySource, error) {
+	src, err := newRegistrySource(registry.URL)
+	if err != nil {
+		return nil, err
+	}
+	return withHeaders(src, registry.Headers), nil
+}
+
+// withNamedRegistries adds the named registries to the registry source. Remote named registries
+// share the on-disk cache with the default source.
+func withNamedRegistries(src registrySource, registries map[string]NamedRegistry, offline bool) (registrySource, error) {
+	if len(registries) == 0 {
+		return src, nil
+	}
+	var cache *fileCache
+	if cached, ok := src.(*cachedSource); ok {
+		cache = cached.cache
+	}
+	scoped := &scopedSource{defaultSource: src, scopes: make(map[string]registrySource)}
+	for name, registry := range registries {
+		namedSrc, err := newNamedRegistrySource(registry)
+		if err != nil {
+			return nil, fmt.Errorf("registry '%s': %w", name, err)
+		}
+		if _, ok := namedSrc.(dirSource); !ok && cache != nil {
+			namedSrc = &cachedSource{inner: namedSrc, cache: cache, offline: offline}
+		} else {
+			namedSrc, err = withCache(namedSrc, offline)
+			if err != nil {
+				return nil, err
+			}
+			if cached, ok := namedSrc.(*cachedSource); ok {
+				cache = cached.cache
+			}
+		}
+		scoped.scopes[name] = namedSrc
+	}
+	return scoped, nil
+}
+
+// fetchNamedRegistry fetches the registry of a named registry at the given ref, with the names
+// and repository paths of its components and utils prefixed with the registry name.
+func fetchNamedRegistry(scope, ref string) (Registry, error) {
+	if _, ok := namedRegistries[scope]; !ok {
+		return Registry{}, fmt.Errorf("registry '%s' is not configured in %s", scope, configFileName)
+	}
+	registry, err := fetchRegistryFile(ref, qualifiedName(scope, registryPath))
+	if err != nil {
+		return Registry{}, err
+	}
+	return qualifyRegistry(scope, registry), nil
+}
+
+// fetchRegistryFor fetches the registry a component or repository path comes from at the given ref.
+func fetchRegistryFor(name, ref string) (Registry, error) {
+	if scope, _ := splitScope(name); scope != "" {
+		return fetchNamedRegistry(scope, ref)
+	}
+	return fetchRegistry(ref)
+}
+
+// qualifyRegistry prefixes the names and repository paths of a named registry with its name.
+// Dependencies and required utils the registry doesn't define itself refer to the default registry,
+// unless they name another registry.
+func qualifyRegistry(scope string, registry Registry) Registry {
+	qualify := func(repoPath string) string { return qualifiedName(scope, repoPath) }
+	ownComponents := make(map[string]bool)
+	for _, comp := range registry.Components {
+		ownComponents[comp.Name] = true
+	}
+	ownUtils := make(map[string]bool)
+	qualified := registry
+	qualified.Components, qualified.Utils = nil, nil
+	for _, util := range registry.Utils {
+		ownUtils[util.Path] = true
+		util.Path = qualify(util.Path)
+		qualified.Utils = append(qualified.Utils, util)
+	}
+
+	for _, comp := range registry.Components {
+		comp.Name = qualify(comp.Name)
+		files := make([]string, len(comp.Files))
+		for i, file := range comp.Files {
+			files[i] = qualify(file)
+		}
+		comp.Files = files
+		var deps []string
+		for _, dep := range comp.Dependencies {
+			if ownComponents[dep] {
+				dep = qualify(dep)
+			}
+			deps = append(deps, dep)
+		}
+		comp.Dependencies = deps
+		var utils []string
+		for _, util := range comp.RequiredUtils {
+			if ownUtils[util] {
+				util = qualify(util)
+			}
+			utils = append(utils, util)
+		}
+		comp.RequiredUtils = utils
+		if comp.Hashes != nil {
+			hashes := make(map[string]string, len(comp.Hashes))
+			for repoPath, sha := range comp.Hashes {
+				hashes[qualify(repoPath)] = sha
+			}
+			comp.Hashes = hashes
+		}
+		qualified.Components = append(qualified.Components, comp)
+	}
+	return qualified
+}
+
+// addNamedRegistries adds the components of the named registries that the given components, or their
+// dependencies, come from to componentMap, and the hashes of their files to hashes.
+func addNamedRegistries(names []string, componentMap map[string]ComponentDef, hashes map[string]string) error {
+	loaded := make(map[string]bool)
+	pending := slices.Clone(names)
+	for len(pending) > 0 {
+		scope, _ := splitScope(pending[0])
+		pending = pending[1:]
+		if scope == "" || loaded[scope] {
+			continue
+		}
+		loaded[scope] = true
+		if _, ok := namedRegistries[scope]; !ok {
+			return fmt.Errorf("registry '%s' is not configured in %s", scope, configFileName)
+		}
+
+		ref := namedRegistryRef(scope)
+		fmt.Printf("🔍 Fetching component registry '%s' (ref: %s)...\n", scope, ref)
+		registry, err := fetchNamedRegistry(scope, ref)
+		if err == nil {
+			err = checkCLIVersion(registry)
+		}
+		if err != nil {
+			return fmt.Errorf("registry '%s': %w", scope, err)
+		}
+		for _, comp := range registry.Components {
+			componentMap[comp.Name] = comp
+			pending = append(pending, comp.Dependencies...)
+		}
+		maps.Copy(hashes, registry.FileHashes())
+	}
+	return nil
+}
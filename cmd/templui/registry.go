@@ -1,80 +1,154 @@
 package main
 
 import (
-	"encoding/json"
+	"cmp"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-)
+	"strconv"
+	"strings"
 
-// Registry defines the structure of the registry.json file.
-type Registry struct {
-	Components []ComponentDef `json:"components"`
-	Utils      []UtilDef      `json:"utils"`
-}
+	registrydef "github.com/templui/templui/internal/registry"
+)
 
-// ComponentDef describes a single component within the registry.
-type ComponentDef struct {
-	Name          string   `json:"name"`
-	Slug          string   `json:"slug"`
-	DisplayName   string   `json:"displayName"`
-	Description   string   `json:"description"`
-	Files         []string `json:"files"`           // Paths relative to the repository root
-	Dependencies  []string `json:"dependencies"`    // Names of other required components
-	RequiredUtils []string `json:"requiredUtils"`   // Paths to required utils relative to the repository root
-	HasJS         bool     `json:"hasJS,omitempty"` // Whether this component requires JavaScript
-}
+// The registry types are shared with the docs site.
+type (
+	Registry     = registrydef.Registry
+	ComponentDef = registrydef.ComponentDef
+	UtilDef      = registrydef.UtilDef
+)
 
-// UtilDef describes a single utility file within the registry.
-type UtilDef struct {
-	Path        string `json:"path"` // Path relative to the repository root
-	Description string `json:"description"`
-}
+// source is the registry source used by all commands (selected in main).
+var source registrySource = httpSource{baseURL: rawContentBaseURL}
 
 // fetchRegistry downloads and parses the registry.json file for a given git ref.
 func fetchRegistry(ref string) (Registry, error) {
-	registryURL := rawContentBaseURL + ref + "/" + registryPath
-	resp, err := http.Get(registryURL)
+	return fetchRegistryFile(ref, registryPath)
+}
+
+// fetchRegistryFile downloads and parses a registry file at the given repository path.
+func fetchRegistryFile(ref, repoPath string) (Registry, error) {
+	registryURL := source.location(ref, repoPath)
+	body, err := source.fetch(ref, repoPath)
 	if err != nil {
-		return Registry{}, fmt.Errorf("failed to start download: %w", err)
+		return Registry{}, fmt.Errorf("failed to fetch registry: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return Registry{}, fmt.Errorf("failed to download registry from %s: status code %d, message: %s", registryURL, resp.StatusCode, string(bodyBytes))
+	registry, err := registrydef.Parse(body)
+	var unsupported *registrydef.UnsupportedSchemaError
+	if errors.As(err, &unsupported) {
+		return Registry{}, fmt.Errorf("the registry at %s uses schema version %d, but templui %s only supports up to version %d. Run 'templui upgrade' to update the CLI",
+			registryURL, unsupported.Version, version, registrydef.SchemaVersion)
 	}
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return Registry{}, fmt.Errorf("failed to read response body: %w", err)
+		return Registry{}, fmt.Errorf("invalid registry (from %s):\n%w", registryURL, err)
 	}
 
-	var registry Registry
-	err = json.Unmarshal(body, &registry)
-	if err != nil {
-		return Registry{}, fmt.Errorf("failed to parse registry JSON (from %s): %w", registryURL, err)
+	return registry, nil
+}
+
+// checkCLIVersion returns an error if a registry requires a newer CLI to install its components.
+// Development builds are not checked.
+func checkCLIVersion(registry Registry) error {
+	if registry.MinCLIVersion == "" || !isReleaseVersion(version) {
+		return nil
+	}
+	if compareVersions(version, registry.MinCLIVersion) < 0 {
+		return fmt.Errorf("this registry requires templui %s or newer, but you have %s. Run 'templui upgrade' to update the CLI",
+			registry.MinCLIVersion, version)
 	}
+	return nil
+}
 
-	return registry, nil
+// isReleaseVersion reports whether v is a version like v1.2.3, a pre-release or a pseudo-version
+// of a commit after a release. Local builds (v0.0.0-..., +dirty or "dev") are not.
+func isReleaseVersion(v string) bool {
+	v, build, _ := strings.Cut(v, "+")
+	return semverTagRegex.MatchString(v) && !strings.HasPrefix(v, "v0.0.0-") && build != "dirty"
+}
+
+// compareVersions compares two versions like v1.2.3 or v1.2.3-rc.1 and returns -1, 0 or 1.
+// Pre-releases sort before their release and are compared as strings.
+func compareVersions(a, b string) int {
+	parse := func(v string) ([3]int, string) {
+		v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "+")
+		core, pre, _ := strings.Cut(v, "-")
+		var parts [3]int
+		for i, part := range strings.SplitN(core, ".", 3) {
+			parts[i], _ = strconv.Atoi(part)
+		}
+		return parts, pre
+	}
+	aParts, aPre := parse(a)
+	bParts, bPre := parse(b)
+	if c := cmp.Compare(aParts[0], bParts[0]); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(aParts[1], bParts[1]); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(aParts[2], bParts[2]); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return cmp.Compare(aPre, bPre)
+}
+
+// errIntegrity is wrapped when fetched content doesn't match the hash published in the registry.
+var errIntegrity = errors.New("integrity check failed")
+
+// fetchVerified fetches a file from the registry source and checks it against its published hash.
+// Files without a published hash are accepted as-is, callers writing them warn about it.
+func fetchVerified(ref, repoPath string, hashes map[string]string) ([]byte, error) {
+	data, err := source.fetch(ref, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	expected := hashes[repoPath]
+	if expected == "" {
+		return data, nil
+	}
+	if actual := hashContent(data); actual != expected {
+		return nil, fmt.Errorf("%w for %s: expected sha256 %s, got %s", errIntegrity, source.location(ref, repoPath), expected, actual)
+	}
+	return data, nil
 }
 
 // downloadFile fetches the content of a single file from a URL.
 func downloadFile(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+	return downloadWithHeaders(url, nil)
+}
+
+// downloadWithHeaders fetches the content of a single file from a URL, sending the given HTTP headers.
+func downloadWithHeaders(url string, headers map[string]string) ([]byte, error) {
+	resp, data, err := httpClient.get(url, headers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start download from %s: %w", url, err)
+		return nil, fmt.Errorf("failed to download file from %s: %w", url, err)
 	}
-	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed to download file from %s: status code 404: %w", url, errNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to download file from %s: status code %d, message: %s", url, resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("failed to download file from %s: status code %d, message: %s%s", url, resp.StatusCode, string(data), authHint(resp))
 	}
+	return data, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from %s: %w", url, err)
+// authHint suggests setting GITHUB_TOKEN for GitHub responses that hit the rate limit of unauthenticated requests.
+func authHint(resp *http.Response) string {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return ""
 	}
-	return data, nil
+	if !githubHosts[resp.Request.URL.Hostname()] || resp.Request.Header.Get("Authorization") != "" {
+		return ""
+	}
+	return " (set GITHUB_TOKEN to raise the GitHub rate limit)"
 }
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	registrydef "github.com/templui/templui/internal/registry"
+)
+
+// runRegistry handles the 'registry' command logic and returns the exit code.
+func runRegistry(args []string, check, withHashes, force bool) int {
+	if len(args) == 0 || args[0] != "build" || len(args) > 2 {
+		failf("Error: Unknown or missing registry action.\n")
+		fmt.Println("Usage: templui registry build [<repository-dir>]")
+		return exitUsage
+	}
+	root := "."
+	if len(args) == 2 {
+		root = args[1]
+	}
+	return buildRegistry(root, check, withHashes, force)
+}
+
+// buildRegistry generates the registry.json of a repository from its components and utils,
+// keeping the hand-written metadata of the existing file. Nothing is written if the tree
+// is inconsistent, or with check, where it only reports whether the file is up to date.
+// Files, dependencies, required utils or hasJS of existing components are only overwritten
+// after confirmation, or with force.
+func buildRegistry(root string, check, withHashes, force bool) int {
+	registryFile := filepath.Join(root, filepath.FromSlash(registryPath))
+	existingData, err := os.ReadFile(registryFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		failf("❌ Error reading %s: %v\n", registryFile, err)
+		return exitError
+	}
+	var existing Registry
+	if existingData != nil {
+		existing, err = registrydef.Parse(existingData)
+		if err != nil {
+			failf("❌ Error parsing %s: %v\n", registryFile, err)
+			return exitError
+		}
+	}
+
+	// Hashes stay published once a registry publishes them.
+	withHashes = withHashes || len(existing.FileHashes()) > 0
+	prefix := moduleImportPrefix(root)
+	fmt.Printf("🔍 Scanning %s (imports: %s)...\n", filepath.Join(root, filepath.FromSlash(repoComponentBasePath)), prefix)
+	registry, warnings, problems := scanRegistry(root, prefix, existing, existingData != nil, withHashes)
+	result.Components = registry.Components
+	result.Utils = registry.Utils
+
+	for _, warning := range warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	for _, problem := range problems {
+		failf("❌ %s\n", problem)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("\n%d inconsistencies found, %s was not written.\n", len(problems), registryFile)
+		return exitError
+	}
+
+	added, changed := registryChanges(existing, registry)
+	for _, name := range added {
+		fmt.Printf("  + %s\n", name)
+	}
+	if len(changed) > 0 {
+		fmt.Printf("⚠️  The components differ from %d field(s) in %s:\n", len(changed), registryFile)
+		for _, change := range changed {
+			fmt.Printf("  ~ %s\n", change)
+		}
+	}
+	result.Changes = changed
+
+	data, err := encodeRegistry(registry)
+	if err != nil {
+		failf("❌ Error encoding registry: %v\n", err)
+		return exitError
+	}
+	if bytes.Equal(data, existingData) {
+		fmt.Printf("✅ %s is up to date (%d components, %d utils).\n", registryFile, len(registry.Components), len(registry.Utils))
+		return exitOK
+	}
+	if check {
+		failf("❌ %s is out of date. Run 'templui registry build' to update it.\n", registryFile)
+		return exitError
+	}
+	if len(changed) > 0 && !force && !askYesNo(fmt.Sprintf("Overwrite these fields in %s?", registryFile)) {
+		failf("❌ %s was not written. Fix the components, or rerun with --force to take the fields from them.\n", registryFile)
+		return exitError
+	}
+	if err := os.MkdirAll(filepath.Dir(registryFile), 0755); err != nil {
+		failf("❌ Error creating directory for %s: %v\n", registryFile, err)
+		return exitError
+	}
+	if err := os.WriteFile(registryFile, data, 0644); err != nil {
+		failf("❌ Error writing %s: %v\n", registryFile, err)
+		return exitError
+	}
+	fmt.Printf("✅ Wrote %s (%d components, %d utils).\n", registryFile, len(registry.Components), len(registry.Utils))
+	return exitOK
+}
+
+// moduleImportPrefix returns the import path prefix of the internal packages of a repository,
+// read from its go.mod. Repositories without a go.mod are assumed to be templUI itself.
+func moduleImportPrefix(root string) string {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return internalImportPrefix
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module ")
+		if !ok {
+			continue
+		}
+		module = strings.TrimSpace(module)
+		if unquoted, err := strconv.Unquote(module); err == nil {
+			module = unquoted
+		}
+		return module + "/internal/"
+	}
+	return internalImportPrefix
+}
+
+// scanRegistry builds the registry of a repository in the current schema version. Files, dependencies,
+// required utils and hasJS are derived from the components tree, the remaining metadata and the list of
+// published utils come from the existing registry. Components new to the registry are added with default metadata.
+// Utils are scanned from the utils directory only if there is no existing registry.
+func scanRegistry(root, prefix string, existing Registry, hasExisting, withHashes bool) (Registry, []string, []string) {
+	var warnings, problems []string
+	componentsDir := filepath.Join(root, filepath.FromSlash(repoComponentBasePath))
+
+	entries, err := os.ReadDir(componentsDir)
+	if err != nil {
+		return Registry{}, nil, []string{fmt.Sprintf("Cannot read components directory: %v", err)}
+	}
+	onDisk := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			onDisk[entry.Name()] = true
+		}
+	}
+
+	// Keep the order of the existing registry and append new components sorted by name.
+	metadata := make(map[string]ComponentDef)
+	var names []string
+	for _, comp := range existing.Components {
+		if !onDisk[comp.Name] {
+			problems = append(problems, fmt.Sprintf("'%s' is listed in the registry, but %s%s/ doesn't exist", comp.Name, repoComponentBasePath, comp.Name))
+			continue
+		}
+		metadata[comp.Name] = comp
+		names = append(names, comp.Name)
+	}
+	var added []string
+	for name := range onDisk {
+		if _, ok := metadata[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	slices.Sort(added)
+	for _, name := range added {
+		warnings = append(warnings, fmt.Sprintf("New component '%s' added with default metadata, add its description, categories and tags to %s", name, registryPath))
+		names = append(names, name)
+	}
+
+	utils := existing.Utils
+	if !hasExisting {
+		utils, err = scanUtils(root)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Cannot read utils directory: %v", err))
+		}
+	}
+	registry := Registry{
+		Schema:        existing.Schema,
+		SchemaVersion: registrydef.SchemaVersion,
+		MinCLIVersion: existing.MinCLIVersion,
+		Components:    []ComponentDef{},
+		Utils:         []UtilDef{},
+	}
+	if registry.Schema == "" {
+		registry.Schema = registrydef.SchemaURL
+	}
+	for _, util := range utils {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(util.Path)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Util '%s' is listed in the registry, but can't be read: %v", util.Path, err))
+			continue
+		}
+		util.SHA256 = ""
+		if withHashes {
+			util.SHA256 = hashContent(data)
+		}
+		registry.Utils = append(registry.Utils, util)
+	}
+
+	for _, name := range names {
+		comp, ok := metadata[name]
+		if !ok {
+			comp = ComponentDef{Name: name, Slug: name, DisplayName: defaultDisplayName(name)}
+		}
+		compProblems := scanComponent(root, prefix, &comp, onDisk, registry.Utils, withHashes)
+		problems = append(problems, compProblems...)
+		if ok && comp.Description == "" {
+			warnings = append(warnings, fmt.Sprintf("Component '%s' has no description", name))
+		}
+		registry.Components = append(registry.Components, comp)
+	}
+	if err := registry.Validate(); err != nil {
+		problems = append(problems, strings.Split(err.Error(), "\n")...)
+	}
+	return registry, warnings, problems
+}
+
+// scanComponent derives the files, dependencies, required utils, hasJS and optionally the hashes
+// of a component from its directory and the imports of its .templ and .go files.
+func scanComponent(root, prefix string, comp *ComponentDef, components map[string]bool, utils []UtilDef, withHashes bool) []string {
+	var problems []string
+	componentDir := filepath.Join(root, filepath.FromSlash(repoComponentBasePath), comp.Name)
+	files := []string{}
+	deps := make(map[string]bool)
+	requiredUtils := make(map[string]bool)
+	hashes := make(map[string]string)
+
+	err := filepath.WalkDir(componentDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		isSource := strings.HasSuffix(name, ".templ") ||
+			(strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_templ.go") && !strings.HasSuffix(name, "_test.go"))
+		if !isSource {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		repoPath := filepath.ToSlash(rel)
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, repoPath)
+		hashes[repoPath] = hashContent(data)
+
+		_, file, err := parseFileHeader(data)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Cannot parse the imports of %s: %v", repoPath, err))
+			return nil
+		}
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			internalPath, ok := strings.CutPrefix(importPath, prefix)
+			if !ok {
+				// Components of other repositories may use templUI's components, which are installed from the
+				// default registry. templUI's utils are installed by 'templui init' and aren't listed.
+				dep, isTemplUI := strings.CutPrefix(importPath, internalImportPrefix+"components/")
+				if !isTemplUI || prefix == internalImportPrefix {
+					continue
+				}
+				dep, _, _ = strings.Cut(dep, "/")
+				if components[dep] {
+					problems = append(problems, fmt.Sprintf("%s imports templUI's component '%s', which has the same name as a component of this registry", repoPath, dep))
+					continue
+				}
+				deps[dep] = true
+				continue
+			}
+			if componentPath, ok := strings.CutPrefix(internalPath, "components/"); ok {
+				dep, _, _ := strings.Cut(componentPath, "/")
+				if dep == comp.Name {
+					continue
+				}
+				if !components[dep] {
+					problems = append(problems, fmt.Sprintf("%s imports component '%s', which doesn't exist", repoPath, dep))
+					continue
+				}
+				deps[dep] = true
+				continue
+			}
+			if internalPath == "utils" || strings.HasPrefix(internalPath, "utils/") {
+				utilsDir := "internal/" + internalPath
+				found := false
+				for _, util := range utils {
+					if path.Dir(util.Path) == utilsDir {
+						requiredUtils[util.Path] = true
+						found = true
+					}
+				}
+				if !found {
+					problems = append(problems, fmt.Sprintf("%s imports %s, but the registry lists no util in that package", repoPath, importPath))
+				}
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s imports %s, which is neither a component nor a util", repoPath, importPath))
+		}
+		return nil
+	})
+	if err != nil {
+		return append(problems, fmt.Sprintf("Cannot scan component '%s': %v", comp.Name, err))
+	}
+	if len(files) == 0 {
+		problems = append(problems, fmt.Sprintf("Component '%s' has no .templ or .go files", comp.Name))
+	}
+
+	jsPath := filepath.Join(componentDir, comp.Name+".min.js")
+	jsData, jsErr := os.ReadFile(jsPath)
+	comp.HasJS = jsErr == nil
+	if _, err := os.Stat(filepath.Join(componentDir, comp.Name+".js")); err == nil && !comp.HasJS {
+		problems = append(problems, fmt.Sprintf("Component '%s' has %s.js but no %s.min.js (run 'task minify-js-components')", comp.Name, comp.Name, comp.Name))
+	}
+	if comp.HasJS {
+		hashes[componentJSRepoPath(comp.Name)] = hashContent(jsData)
+	}
+
+	comp.Files = keepOrder(comp.Files, files)
+	comp.Dependencies = append([]string{}, slices.Sorted(maps.Keys(deps))...)
+	comp.RequiredUtils = nil
+	if len(requiredUtils) > 0 {
+		comp.RequiredUtils = slices.Sorted(maps.Keys(requiredUtils))
+	}
+	if comp.Categories == nil {
+		comp.Categories = []string{}
+	}
+	if comp.Tags == nil {
+		comp.Tags = []string{}
+	}
+	comp.Hashes = nil
+	if withHashes {
+		comp.Hashes = hashes
+	}
+	return problems
+}
+
+// scanUtils lists the Go files of the utils directory of a repository as utils.
+func scanUtils(root string) ([]UtilDef, error) {
+	var utils []UtilDef
+	err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(repoUtilBasePath)), func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".go") || strings.HasSuffix(d.Name(), "_test.go") {
+			return err
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		utils = append(utils, UtilDef{Path: filepath.ToSlash(rel)})
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return utils, err
+}
+
+// registryChanges returns the components added to a registry and describes how the derived fields
+// of the other components differ between two registries.
+func registryChanges(before, after Registry) ([]string, []string) {
+	previous := make(map[string]ComponentDef)
+	for _, comp := range before.Components {
+		previous[comp.Name] = comp
+	}
+	var added, changes []string
+	for _, comp := range after.Components {
+		old, ok := previous[comp.Name]
+		if !ok {
+			added = append(added, comp.Name)
+			continue
+		}
+		for _, field := range []struct {
+			name     string
+			old, new []string
+		}{
+			{"files", old.Files, comp.Files},
+			{"dependencies", old.Dependencies, comp.Dependencies},
+			{"requiredUtils", old.RequiredUtils, comp.RequiredUtils},
+		} {
+			var diff []string
+			for _, value := range field.new {
+				if !slices.Contains(field.old, value) {
+					diff = append(diff, "+"+value)
+				}
+			}
+			for _, value := range field.old {
+				if !slices.Contains(field.new, value) {
+					diff = append(diff, "-"+value)
+				}
+			}
+			if len(diff) > 0 {
+				changes = append(changes, fmt.Sprintf("%s: %s %s", comp.Name, field.name, strings.Join(diff, " ")))
+			}
+		}
+		if old.HasJS != comp.HasJS {
+			changes = append(changes, fmt.Sprintf("%s: hasJS %t → %t", comp.Name, old.HasJS, comp.HasJS))
+		}
+	}
+	return added, changes
+}
+
+// registryLineWidth is the width up to which encodeRegistry keeps lists of strings on one line.
+const registryLineWidth = 80
+
+// encodeRegistry encodes a registry as indented JSON. Lists of strings that fit on one line
+// are kept on one line, as in the hand-written registry.json.
+func encodeRegistry(registry Registry) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(registry); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if !strings.HasSuffix(line, "[") {
+			out = append(out, line)
+			continue
+		}
+		// Collect the elements up to the closing bracket, as long as they are strings.
+		var elements []string
+		end := i + 1
+		for ; end < len(lines); end++ {
+			element := strings.TrimSpace(lines[end])
+			if strings.HasPrefix(element, "]") || !strings.HasPrefix(element, `"`) {
+				break
+			}
+			elements = append(elements, strings.TrimSuffix(element, ","))
+		}
+		closing := strings.TrimSpace(lines[end])
+		if !strings.HasPrefix(closing, "]") {
+			out = append(out, line)
+			continue
+		}
+		joined := line + strings.Join(elements, ", ") + closing
+		if len(joined) > registryLineWidth {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, joined)
+		i = end
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// keepOrder returns the values in the order of previous, with values not in previous appended sorted.
+// The result is never nil, so an empty list is encoded as [].
+func keepOrder(previous, values []string) []string {
+	ordered := []string{}
+	for _, value := range previous {
+		if slices.Contains(values, value) && !slices.Contains(ordered, value) {
+			ordered = append(ordered, value)
+		}
+	}
+	var added []string
+	for _, value := range values {
+		if !slices.Contains(ordered, value) {
+			added = append(added, value)
+		}
+	}
+	slices.Sort(added)
+	return append(ordered, added...)
+}
+
+// defaultDisplayName derives a display name from a component name, e.g., "Datagrid" for "datagrid".
+func defaultDisplayName(name string) string {
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
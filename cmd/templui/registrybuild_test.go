@@ -0,0 +1,243 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	registrydef "github.com/templui/templui/internal/registry"
+)
+
+// writeTree writes files relative to root, creating their directories.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// withStdin replaces os.Stdin with the given input for the rest of the test.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
+
+// newRegistryRepo creates a repository with a button component and a card component
+// that imports button and the utils and has JavaScript.
+func newRegistryRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"go.mod":                    "module example.com/ui\n\ngo 1.24\n",
+		"internal/utils/templui.go": "package utils\n",
+		"internal/components/button/button.templ": "package button\n\ntempl Button() {\n\t<button></button>\n}\n",
+		"internal/components/card/card.templ": "package card\n\nimport (\n\t\"example.com/ui/internal/components/button\"\n\t\"example.com/ui/internal/utils\"\n)\n\n" +
+			"templ Card() {\n\t@button.Button()\n}\n",
+		"internal/components/card/card.js":     "console.log('card')\n",
+		"internal/components/card/card.min.js": "console.log('card')",
+	})
+	return root
+}
+
+// readRegistryFile parses the registry.json of a repository.
+func readRegistryFile(t *testing.T, root string) (Registry, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(registryPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := registrydef.Parse(data)
+	if err != nil {
+		t.Fatalf("generated registry is invalid: %v", err)
+	}
+	return registry, data
+}
+
+// registryComponent returns the component of a registry with the given name.
+func registryComponent(t *testing.T, registry Registry, name string) ComponentDef {
+	t.Helper()
+	for _, comp := range registry.Components {
+		if comp.Name == name {
+			return comp
+		}
+	}
+	t.Fatalf("component %s not found", name)
+	return ComponentDef{}
+}
+
+func TestBuildRegistry(t *testing.T) {
+	result = &commandResult{}
+	root := newRegistryRepo(t)
+
+	if code := buildRegistry(root, true, false, false); code != exitError {
+		t.Errorf("check without registry.json exited with %d, want %d", code, exitError)
+	}
+	if code := buildRegistry(root, false, true, false); code != exitOK {
+		t.Fatalf("build exited with %d: %v", code, result.Errors)
+	}
+
+	registry, _ := readRegistryFile(t, root)
+	card := registryComponent(t, registry, "card")
+	if !slices.Equal(card.Dependencies, []string{"button"}) {
+		t.Errorf("card dependencies = %v, want [button]", card.Dependencies)
+	}
+	if !slices.Equal(card.RequiredUtils, []string{"internal/utils/templui.go"}) {
+		t.Errorf("card requiredUtils = %v, want [internal/utils/templui.go]", card.RequiredUtils)
+	}
+	if !card.HasJS {
+		t.Error("card hasJS = false, want true")
+	}
+	if card.Hashes[componentJSRepoPath("card")] == "" || card.Hashes["internal/components/card/card.templ"] == "" {
+		t.Errorf("card hashes = %v, want the templ and JavaScript files", card.Hashes)
+	}
+	if button := registryComponent(t, registry, "button"); button.HasJS || len(button.Dependencies) != 0 {
+		t.Errorf("button = %+v, want no dependencies and no JavaScript", button)
+	}
+
+	result = &commandResult{}
+	if code := buildRegistry(root, true, false, false); code != exitOK {
+		t.Errorf("check after build exited with %d: %v", code, result.Errors)
+	}
+
+	// Changed components make the check fail.
+	writeTree(t, root, map[string]string{"internal/components/button/button.templ": "package button\n\ntempl Button() {\n\t<button type=\"button\"></button>\n}\n"})
+	if code := buildRegistry(root, true, false, false); code != exitError {
+		t.Errorf("check of outdated hashes exited with %d, want %d", code, exitError)
+	}
+}
+
+func TestBuildRegistryHandMaintainedFields(t *testing.T) {
+	result = &commandResult{}
+	root := newRegistryRepo(t)
+	if code := buildRegistry(root, false, false, false); code != exitOK {
+		t.Fatalf("build exited with %d: %v", code, result.Errors)
+	}
+
+	// Hand-edit card: drop its dependency and JavaScript.
+	registry, _ := readRegistryFile(t, root)
+	for i := range registry.Components {
+		if registry.Components[i].Name == "card" {
+			registry.Components[i].Dependencies = []string{}
+			registry.Components[i].HasJS = false
+		}
+	}
+	edited, err := encodeRegistry(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, root, map[string]string{registryPath: string(edited)})
+
+	result = &commandResult{}
+	withStdin(t, "n\n")
+	if code := buildRegistry(root, false, false, false); code != exitError {
+		t.Errorf("declined build exited with %d, want %d", code, exitError)
+	}
+	want := []string{"card: dependencies +button", "card: hasJS false → true"}
+	if !slices.Equal(result.Changes, want) {
+		t.Errorf("changes = %q, want %q", result.Changes, want)
+	}
+	if _, data := readRegistryFile(t, root); string(data) != string(edited) {
+		t.Error("declined build overwrote registry.json")
+	}
+
+	result = &commandResult{}
+	if code := buildRegistry(root, false, false, true); code != exitOK {
+		t.Fatalf("forced build exited with %d: %v", code, result.Errors)
+	}
+	registry, _ = readRegistryFile(t, root)
+	if card := registryComponent(t, registry, "card"); !card.HasJS || !slices.Equal(card.Dependencies, []string{"button"}) {
+		t.Errorf("card after forced build = %+v, want the fields of the components", card)
+	}
+}
+
+func TestBuildRegistryProblems(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "unknown component import",
+			files: map[string]string{"internal/components/badge/badge.templ": "package badge\n\nimport \"example.com/ui/internal/components/missing\"\n"},
+		},
+		{
+			name:  "unknown internal package",
+			files: map[string]string{"internal/components/badge/badge.templ": "package badge\n\nimport \"example.com/ui/internal/other\"\n"},
+		},
+		{
+			name:  "JavaScript without minified file",
+			files: map[string]string{"internal/components/badge/badge.templ": "package badge\n", "internal/components/badge/badge.js": "x()\n"},
+		},
+		{
+			name:  "unparsable imports",
+			files: map[string]string{"internal/components/badge/badge.templ": "package badge\n\nimport (\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result = &commandResult{}
+			root := newRegistryRepo(t)
+			writeTree(t, root, tt.files)
+			if code := buildRegistry(root, false, false, true); code != exitError {
+				t.Errorf("build exited with %d, want %d", code, exitError)
+			}
+			if len(result.Errors) == 0 {
+				t.Error("build reported no problems")
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(registryPath))); !os.IsNotExist(err) {
+				t.Errorf("registry.json was written despite problems: %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeRegistryKeepsShortListsOnOneLine(t *testing.T) {
+	registry := Registry{
+		SchemaVersion: registrydef.SchemaVersion,
+		Components: []ComponentDef{{
+			Name: "card", DisplayName: "Card", Description: "Card.", Slug: "card",
+			Files: []string{"internal/components/card/card.templ"}, Dependencies: []string{},
+		}},
+		Utils: []UtilDef{},
+	}
+	data, err := encodeRegistry(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`      "files": ["internal/components/card/card.templ"],`,
+		`      "dependencies": [],`,
+	} {
+		if !slices.Contains(splitLinesTrimmed(string(data)), line) {
+			t.Errorf("encoded registry has no line %q:\n%s", line, data)
+		}
+	}
+}
+
+// splitLinesTrimmed splits text into lines without their terminators.
+func splitLinesTrimmed(text string) []string {
+	var lines []string
+	for _, line := range splitLines(text) {
+		lines = append(lines, line[:len(line)-len("\n")])
+	}
+	return lines
+}
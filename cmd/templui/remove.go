@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// runRemove handles the 'remove' command logic.
+func runRemove(args []string, force bool, prune bool) {
+	componentsToRemove := args
+	if len(componentsToRemove) == 0 && !prune {
+		failf("Error: No component(s) specified after 'remove'.\n")
+		fmt.Println("Usage: templui remove <component>... | templui remove --prune [<component>...]")
+		return
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		failf("Error loading config: %v\n", err)
+		return
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return
+	}
+
+	installedNames, err := getInstalledComponentNames(config, lock)
+	if err != nil {
+		failf("Error detecting installed components: %v\n", err)
+		return
+	}
+	deps := installedDependencies(installedNames, lock)
+
+	// Validate all requested components before deleting anything.
+	for _, name := range componentsToRemove {
+		if !slices.Contains(installedNames, name) {
+			failf("❌ Component '%s' is not installed.\n", name)
+			return
+		}
+		dependents := dependentsOf(name, installedNames, componentsToRemove, deps)
+		if len(dependents) == 0 {
+			continue
+		}
+		if !force {
+			failf("❌ Component '%s' is required by: %s\n", name, strings.Join(dependents, ", "))
+			fmt.Println("   Remove those components as well, or use --force to remove it anyway.")
+			return
+		}
+		fmt.Printf("⚠️  Component '%s' is required by %s. Removing anyway (--force specified).\n", name, strings.Join(dependents, ", "))
+	}
+
+	// Ask before deleting local changes, and don't delete files that aren't known to belong to a component.
+	var files []LockedFile
+	for _, name := range componentsToRemove {
+		componentFiles, err := removableFiles(config, lock, name, deps[name])
+		if err != nil {
+			failf("❌ Can't remove component %s: %v\n", name, err)
+			return
+		}
+		files = append(files, componentFiles...)
+	}
+	if !confirmRemoval(files, force) {
+		failf("❌ Nothing was removed.\n")
+		fmt.Println("   Keep a copy of your changes, or use --force to remove them anyway.")
+		return
+	}
+
+	for _, name := range componentsToRemove {
+		err := removeComponent(config, lock, name, deps[name])
+		if err != nil {
+			failf("❌ Error removing component %s: %v\n", name, err)
+			return
+		}
+		installedNames = slices.DeleteFunc(installedNames, func(n string) bool { return n == name })
+		result.Removed = append(result.Removed, name)
+	}
+
+	// Find dependencies and utils that nothing installed needs anymore.
+	orphans := orphanedDependencies(installedNames, lock, deps)
+	orphanedUtils := orphanedUtils(installedNames, lock, deps)
+	if prune && !confirmRemoval(prunedFiles(config, lock, orphans, orphanedUtils, deps), force) {
+		fmt.Println("💡 Kept the unused dependencies and utils. Run 'templui remove --prune' again to remove them.")
+	} else if prune {
+		for _, name := range orphans {
+			fmt.Printf("🧹 Pruning unused dependency: %s\n", name)
+			err := removeComponent(config, lock, name, deps[name])
+			if err != nil {
+				failf("❌ Error removing component %s: %v\n", name, err)
+				return
+			}
+			result.Pruned = append(result.Pruned, name)
+		}
+		for _, repoUtilPath := range orphanedUtils {
+			fmt.Printf("🧹 Pruning unused util: %s\n", lock.Utils[repoUtilPath].Path)
+			err := removeFile(lock.Utils[repoUtilPath].Path)
+			if err != nil {
+				failf("❌ Error removing util %s: %v\n", repoUtilPath, err)
+				return
+			}
+			delete(lock.Utils, repoUtilPath)
+			result.Pruned = append(result.Pruned, repoUtilPath)
+		}
+	} else if len(orphans) > 0 || len(orphanedUtils) > 0 {
+		var unused []string
+		unused = append(unused, orphans...)
+		for _, repoUtilPath := range orphanedUtils {
+			unused = append(unused, lock.Utils[repoUtilPath].Path)
+		}
+		fmt.Printf("\n💡 No longer needed by any installed component: %s\n", strings.Join(unused, ", "))
+		fmt.Println("   Run 'templui remove --prune' to remove them.")
+	}
+
+	err = saveLockfile(lock)
+	if err != nil {
+		failf("❌ Error updating %s: %v\n", lockFileName, err)
+		return
+	}
+
+	if len(componentsToRemove) > 0 {
+		fmt.Println("\n💡 Tip: Remove any remaining imports and @component.Script() calls of removed components from your code.")
+	}
+}
+
+// componentDeps holds the dependency information of an installed component.
+type componentDeps struct {
+	dependencies  []string
+	requiredUtils []string
+	files         []string // Registry file paths of components missing from the lockfile
+}
+
+// installedDependencies collects the dependencies of installed components from the lockfile,
+// falling back to the registry of the default ref for components installed without a lockfile.
+func installedDependencies(installedNames []string, lock *Lockfile) map[string]componentDeps {
+	deps := make(map[string]componentDeps)
+	var unlocked []string
+	for _, name := range installedNames {
+		if locked, ok := lock.Components[name]; ok {
+			deps[name] = componentDeps{dependencies: locked.Dependencies, requiredUtils: locked.RequiredUtils}
+		} else {
+			unlocked = append(unlocked, name)
+		}
+	}
+	if len(unlocked) == 0 {
+		return deps
+	}
+
+	registry, err := fetchRegistry(getDefaultRef())
+	if err != nil {
+		fmt.Printf("⚠️  Could not fetch registry to check dependencies of %s: %v\n", strings.Join(unlocked, ", "), err)
+		return deps
+	}
+	for _, name := range unlocked {
+		if comp, ok := findComponent(registry, name); ok {
+			deps[name] = componentDeps{dependencies: comp.Dependencies, requiredUtils: comp.RequiredUtils, files: comp.Files}
+		}
+	}
+	return deps
+}
+
+// dependentsOf returns the installed components (other than those being removed) that depend on name.
+func dependentsOf(name string, installedNames, removing []string, deps map[string]componentDeps) []string {
+	var dependents []string
+	for _, other := range installedNames {
+		if slices.Contains(removing, other) {
+			continue
+		}
+		if slices.Contains(deps[other].dependencies, name) {
+			dependents = append(dependents, other)
+		}
+	}
+	return dependents
+}
+
+// orphanedDependencies returns installed components that were only installed as dependencies
+// and are no longer needed, directly or transitively, by any explicitly installed component.
+func orphanedDependencies(installedNames []string, lock *Lockfile, deps map[string]componentDeps) []string {
+	needed := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if needed[name] {
+			return
+		}
+		needed[name] = true
+		for _, dep := range deps[name].dependencies {
+			visit(dep)
+		}
+	}
+	for _, name := range installedNames {
+		// Components missing from the lockfile are kept, as we can't tell why they were installed.
+		if locked, ok := lock.Components[name]; !ok || locked.Explicit {
+			visit(name)
+		}
+	}
+
+	var orphans []string
+	for _, name := range installedNames {
+		if !needed[name] {
+			orphans = append(orphans, name)
+		}
+	}
+	return orphans
+}
+
+// orphanedUtils returns locked utils that were installed for components and are no longer
+// required by any remaining component. Utils installed directly are never orphaned.
+func orphanedUtils(installedNames []string, lock *Lockfile, deps map[string]componentDeps) []string {
+	orphans := orphanedDependencies(installedNames, lock, deps)
+	required := make(map[string]bool)
+	for _, name := range installedNames {
+		if slices.Contains(orphans, name) {
+			continue
+		}
+		for _, repoUtilPath := range deps[name].requiredUtils {
+			required[repoUtilPath] = true
+		}
+	}
+
+	var unused []string
+	for repoUtilPath, locked := range lock.Utils {
+		if !locked.Explicit && !required[repoUtilPath] {
+			unused = append(unused, repoUtilPath)
+		}
+	}
+	slices.Sort(unused)
+	return unused
+}
+
+// removableFiles returns the files of an installed component that remove deletes, including its JavaScript file:
+// those recorded in the lockfile or, for components installed without one, those listed in the registry.
+func removableFiles(config Config, lock *Lockfile, name string, deps componentDeps) ([]LockedFile, error) {
+	var files []LockedFile
+	jsFile := LockedFile{Path: filepath.ToSlash(componentJSPath(config, name))}
+	if locked, ok := lock.Components[name]; ok {
+		files = append(files, locked.Files...)
+		if locked.JS != nil {
+			jsFile = *locked.JS
+		}
+	} else {
+		if deps.files == nil {
+			return nil, fmt.Errorf("it is neither in %s nor in the registry, so its files are unknown. Delete %s yourself", lockFileName, config.componentDir(name))
+		}
+		for _, repoFilePath := range deps.files {
+			destPath, _ := componentDestPath(config, repoFilePath)
+			files = append(files, LockedFile{Path: filepath.ToSlash(destPath)})
+		}
+	}
+	files = append(files, jsFile)
+
+	for _, file := range files {
+		if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return nil, fmt.Errorf("file '%s' is outside the project", file.Path)
+		}
+	}
+	return files, nil
+}
+
+// prunedFiles returns the files of the unused dependencies and utils that 'remove --prune' deletes.
+// Dependencies whose files are unknown are skipped here and reported when they are removed.
+func prunedFiles(config Config, lock *Lockfile, orphans, orphanedUtils []string, deps map[string]componentDeps) []LockedFile {
+	var files []LockedFile
+	for _, name := range orphans {
+		componentFiles, _ := removableFiles(config, lock, name, deps[name])
+		files = append(files, componentFiles...)
+	}
+	for _, repoUtilPath := range orphanedUtils {
+		files = append(files, lock.Utils[repoUtilPath].LockedFile)
+	}
+	return files
+}
+
+// confirmRemoval warns about files that were changed since they were installed and asks whether
+// to delete them anyway. It returns true if no file was changed or force is set.
+func confirmRemoval(files []LockedFile, force bool) bool {
+	var changed []string
+	for _, file := range files {
+		if file.SHA256 == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.FromSlash(file.Path))
+		if err == nil && hashContent(data) != file.SHA256 {
+			changed = append(changed, file.Path)
+		}
+	}
+	if len(changed) == 0 {
+		return true
+	}
+
+	fmt.Printf("⚠️  %d file(s) were changed since they were installed:\n", len(changed))
+	for _, path := range changed {
+		fmt.Printf("  ~ %s\n", path)
+	}
+	if force {
+		fmt.Println("   Removing them anyway (--force specified).")
+		return true
+	}
+	return askYesNo("Remove them anyway?")
+}
+
+// removeComponent deletes the files of an installed component, including its JavaScript file,
+// and drops it from the lockfile. Other files in the component directory are kept, and so is the directory then.
+func removeComponent(config Config, lock *Lockfile, name string, deps componentDeps) error {
+	files, err := removableFiles(config, lock, name, deps)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err := removeFile(file.Path)
+		if err != nil {
+			return err
+		}
+		// Also remove files generated by 'templ generate'.
+		if strings.HasSuffix(file.Path, ".templ") {
+			err = removeFile(strings.TrimSuffix(file.Path, ".templ") + "_templ.go")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Remove the component directory if nothing else is left in it.
+	compDir := config.componentDir(name)
+	if entries, err := os.ReadDir(compDir); err == nil {
+		if len(entries) == 0 {
+			os.Remove(compDir)
+		} else {
+			fmt.Printf("   Kept %s, it contains files that aren't part of the component\n", compDir)
+		}
+	}
+
+	delete(lock.Components, name)
+	fmt.Printf("✅ Removed component %s\n", name)
+	return nil
+}
+
+// removeFile deletes a single file inside the project, ignoring files that don't exist.
+func removeFile(path string) error {
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		return fmt.Errorf("refusing to remove '%s': it is outside the project", path)
+	}
+	err := os.Remove(filepath.FromSlash(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove '%s': %w", path, err)
+	}
+	fmt.Printf("   Removed %s\n", path)
+	return nil
+}
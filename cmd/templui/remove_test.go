@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// exists reports whether a file or directory exists.
+func exists(path string) bool {
+	_, err := os.Stat(filepath.FromSlash(path))
+	return err == nil
+}
+
+func TestRemoveAsksBeforeDeletingChanges(t *testing.T) {
+	const buttonPath = "internal/components/button/button.templ"
+	server := newRefServer(t, map[string]map[string]string{
+		"v1": {buttonPath: "package button\n\ntempl Button() {\n\t<button></button>\n}\n"},
+	})
+	useSource(t, httpSource{baseURL: server.URL + "/"})
+	config, lock := newTestProject(t)
+	if err := install(config, "v1", []ComponentDef{{Name: "button", Files: []string{buttonPath}}}, nil, &installOptions{lock: lock}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if err := saveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveLockfile(lock); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, ".", map[string]string{
+		"components/button/button.templ": "package button\n\n// Local change\n",
+		"components/button/notes.md":     "Our notes\n",
+	})
+
+	result = &commandResult{}
+	withStdin(t, "n\n")
+	runRemove([]string{"button"}, false, false)
+	if len(result.Errors) == 0 || len(result.Removed) != 0 {
+		t.Errorf("declined removal: errors = %v, removed = %v, want a failure", result.Errors, result.Removed)
+	}
+	if !exists("components/button/button.templ") {
+		t.Fatal("declined removal deleted the changed file")
+	}
+
+	result = &commandResult{}
+	withStdin(t, "y\n")
+	runRemove([]string{"button"}, false, false)
+	if !slices.Equal(result.Removed, []string{"button"}) {
+		t.Fatalf("confirmed removal: errors = %v, removed = %v", result.Errors, result.Removed)
+	}
+	if exists("components/button/button.templ") {
+		t.Error("confirmed removal kept the component file")
+	}
+	if !exists("components/button/notes.md") {
+		t.Error("removal deleted a file that isn't part of the component")
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lock.Components["button"]; ok {
+		t.Error("removed component is still locked")
+	}
+}
+
+func TestRemoveComponentOnlyDeletesItsFiles(t *testing.T) {
+	config, lock := newTestProject(t)
+	writeTree(t, ".", map[string]string{
+		"components/card/card.templ":    "package card\n",
+		"components/card/card_templ.go": "package card\n",
+		"components/card/extra.go":      "package card\n",
+		"components/badge/badge.templ":  "package badge\n",
+		"secret.txt":                    "secret\n",
+	})
+
+	// Unlocked components delete the files listed in the registry.
+	deps := componentDeps{files: []string{"internal/components/card/card.templ"}}
+	if err := removeComponent(config, lock, "card", deps); err != nil {
+		t.Fatalf("removeComponent(card) error: %v", err)
+	}
+	if exists("components/card/card.templ") || exists("components/card/card_templ.go") {
+		t.Error("files of the component were kept")
+	}
+	if !exists("components/card/extra.go") {
+		t.Error("file that isn't in the registry was deleted")
+	}
+
+	// Unlocked components unknown to the registry are left alone.
+	if err := removeComponent(config, lock, "badge", componentDeps{}); err == nil {
+		t.Error("removeComponent(badge) without known files succeeded")
+	}
+	if !exists("components/badge/badge.templ") {
+		t.Error("component with unknown files was deleted")
+	}
+
+	// Locked paths outside the project are refused.
+	lock.Components["badge"] = LockedComponent{Ref: "v1", Files: []LockedFile{{Path: "../secret.txt"}}}
+	if err := removeComponent(config, lock, "badge", componentDeps{}); err == nil {
+		t.Error("removeComponent(badge) with a path outside the project succeeded")
+	}
+	if _, ok := lock.Components["badge"]; !ok {
+		t.Error("component that wasn't removed was dropped from the lockfile")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	registrydef "github.com/templui/templui/internal/registry"
+)
+
+// runSearch handles the 'search' command logic.
+func runSearch(args []string, ref string, categories, tags []string) int {
+	query := strings.Join(args, " ")
+	if strings.TrimSpace(query) == "" {
+		failf("Error: No search query specified after 'search'.\n")
+		fmt.Println("Usage: templui search[@<ref>] [--category <name>] [--tag <name>] <query>")
+		return exitUsage
+	}
+	if ref == "" {
+		ref = getDefaultRef()
+	}
+	result.Ref = ref
+
+	registry, err := fetchRegistry(ref)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			failf("Error: Could not fetch registry: ref '%s' not found or does not contain '%s'.\n", ref, registryPath)
+		} else {
+			failf("Error fetching registry for ref '%s': %v\n", ref, err)
+		}
+		return exitError
+	}
+
+	matches := registrydef.Search(registry.Filter(categories, tags), query)
+	result.Components = matches
+	if len(matches) == 0 {
+		fmt.Printf("No components match '%s'%s.\n", query, describeFilters(categories, tags))
+		return exitOK
+	}
+
+	fmt.Printf("Components matching '%s'%s:\n", query, describeFilters(categories, tags))
+	for _, comp := range matches {
+		printComponentLine(comp)
+	}
+	fmt.Println("\n💡 Run 'templui info <component>' for details.")
+	return exitOK
+}
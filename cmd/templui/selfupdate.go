@@ -0,0 +1,229 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// defaultReleaseURL is where the release archives of the CLI are downloaded from, as <base><tag>/<archive>.
+	defaultReleaseURL = "https://github.com/templui/templui/releases/download/"
+	// releaseURLEnv overrides defaultReleaseURL, e.g. for an internal mirror.
+	releaseURLEnv = "TEMPLUI_RELEASE_URL"
+	// releaseChecksumsFile lists the SHA-256 of every archive of a release, as written by GoReleaser.
+	releaseChecksumsFile = "checksums.txt"
+)
+
+// releaseBaseURL returns the base URL of the release archives: the flag, TEMPLUI_RELEASE_URL or the GitHub releases.
+func releaseBaseURL(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv(releaseURLEnv); env != "" {
+		return env
+	}
+	return defaultReleaseURL
+}
+
+// releaseAssetURL returns the URL of a file of a release. The tag is appended to the base URL,
+// or replaces a {ref} placeholder in it.
+func releaseAssetURL(base, tag, asset string) string {
+	if strings.Contains(base, "{ref}") {
+		return strings.TrimSuffix(strings.ReplaceAll(base, "{ref}", tag), "/") + "/" + asset
+	}
+	return strings.TrimSuffix(base, "/") + "/" + tag + "/" + asset
+}
+
+// releaseArchiveName returns the name of the release archive for a platform, e.g. "templui_linux_amd64.tar.gz".
+func releaseArchiveName(goos, goarch string) string {
+	if goos == "windows" {
+		return fmt.Sprintf("templui_%s_%s.zip", goos, goarch)
+	}
+	return fmt.Sprintf("templui_%s_%s.tar.gz", goos, goarch)
+}
+
+// resolveReleaseTag returns the release tag to upgrade to. "latest" is looked up on GitHub for the
+// default release URL. Mirrors can't list their releases, so it is looked up from the registry source
+// instead, and fails if that has no releases either.
+func resolveReleaseTag(ref, base string, src registrySource) (string, error) {
+	if ref != "" && ref != "latest" {
+		return ref, nil
+	}
+	if base == defaultReleaseURL {
+		src = httpSource{baseURL: rawContentBaseURL}
+	}
+	tag, err := src.latestRelease()
+	if err != nil {
+		return "", err
+	}
+	if tag == "" && base != defaultReleaseURL {
+		return "", fmt.Errorf("the latest release of the mirror %s is unknown, as the registry has no releases", base)
+	}
+	if tag == "" {
+		return "", errors.New("no release found")
+	}
+	return tag, nil
+}
+
+// selfUpdate downloads the release archive of a tag for the current platform, verifies it against the
+// checksums of the release and replaces the running executable with the binary it contains.
+// It returns the path of the replaced executable, or where it would be replaced in dry-run mode.
+func selfUpdate(base, tag string, dryRun bool) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the running executable: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the running executable: %w", err)
+	}
+
+	archiveName := releaseArchiveName(runtime.GOOS, runtime.GOARCH)
+	archiveURL := releaseAssetURL(base, tag, archiveName)
+	if dryRun {
+		fmt.Printf("Would download %s and replace %s\n", archiveURL, exe)
+		return exe, nil
+	}
+
+	fmt.Printf("Downloading %s...\n", archiveURL)
+	archive, err := downloadFile(archiveURL)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return "", fmt.Errorf("no release archive for %s/%s at '%s' (use --go-install to build from source): %w", runtime.GOOS, runtime.GOARCH, tag, err)
+		}
+		return "", err
+	}
+	checksums, err := downloadFile(releaseAssetURL(base, tag, releaseChecksumsFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums: %w", err)
+	}
+	if err := verifyChecksum(archive, checksums, archiveName); err != nil {
+		return "", err
+	}
+
+	binaryName := "templui"
+	if runtime.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+	binary, err := extractBinary(archive, archiveName, binaryName)
+	if err != nil {
+		return "", err
+	}
+	if err := replaceExecutable(exe, binary); err != nil {
+		return "", err
+	}
+	return exe, nil
+}
+
+// verifyChecksum checks an archive against its entry in a checksums file ("<sha256>  <name>" per line).
+func verifyChecksum(archive, checksums []byte, name string) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		if got := hashContent(archive); got != strings.ToLower(fields[0]) {
+			return fmt.Errorf("%w: %s has SHA-256 %s, but the release checksums list %s", errIntegrity, name, got, fields[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not listed in %s", errIntegrity, name, releaseChecksumsFile)
+}
+
+// extractBinary returns the content of the file named binaryName in a .tar.gz or .zip archive.
+func extractBinary(archive []byte, archiveName, binaryName string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", archiveName, err)
+		}
+		for _, file := range reader.File {
+			if filepath.Base(file.Name) != binaryName || file.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract %s from %s: %w", binaryName, archiveName, err)
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("%s does not contain %s", archiveName, binaryName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", archiveName, err)
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s does not contain %s", archiveName, binaryName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", archiveName, err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binaryName {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+// replaceExecutable atomically replaces an executable: the new binary is written next to it and renamed
+// over it, so an interrupted upgrade never leaves a partial file. On Windows, where a running executable
+// can't be overwritten, the old one is moved aside to <exe>.old first.
+func replaceExecutable(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".templui-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s (run with permission to replace %s, or use --go-install): %w", filepath.Dir(exe), exe, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed.
+
+	_, err = tmp.Write(binary)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm()|0111)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write new executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", exe, err)
+		}
+		if err := os.Rename(tmp.Name(), exe); err != nil {
+			os.Rename(old, exe)
+			return fmt.Errorf("failed to replace %s: %w", exe, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
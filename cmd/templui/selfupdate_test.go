@@ -0,0 +1,148 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// releaseSource is a registry source that only knows its latest release.
+type releaseSource struct {
+	dirSource
+	release string
+}
+
+func (s releaseSource) latestRelease() (string, error) {
+	return s.release, nil
+}
+
+func TestResolveReleaseTag(t *testing.T) {
+	const mirror = "https://mirror.example.com/templui/"
+	tests := []struct {
+		name    string
+		ref     string
+		src     registrySource
+		want    string
+		wantErr bool
+	}{
+		{name: "explicit tag", ref: "v1.2.3", src: releaseSource{release: "v2.0.0"}, want: "v1.2.3"},
+		{name: "mirror takes the latest release of the registry", ref: "", src: releaseSource{release: "v2.0.0"}, want: "v2.0.0"},
+		{name: "mirror resolves latest", ref: "latest", src: releaseSource{release: "v2.0.0"}, want: "v2.0.0"},
+		{name: "mirror without releases", ref: "", src: releaseSource{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveReleaseTag(tt.ref, mirror, tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveReleaseTag() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveReleaseTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	archive := []byte("archive")
+	checksums := hashContent([]byte("other")) + "  templui_linux_arm64.tar.gz\n" +
+		strings.ToUpper(hashContent(archive)) + " *templui_linux_amd64.tar.gz\n"
+
+	tests := []struct {
+		name    string
+		archive []byte
+		file    string
+		wantErr bool
+	}{
+		{name: "matches", archive: archive, file: "templui_linux_amd64.tar.gz"},
+		{name: "mismatch", archive: []byte("tampered"), file: "templui_linux_amd64.tar.gz", wantErr: true},
+		{name: "not listed", archive: archive, file: "templui_windows_amd64.zip", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChecksum(tt.archive, []byte(checksums), tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyChecksum() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errIntegrity) {
+				t.Errorf("verifyChecksum() error = %v, want errIntegrity", err)
+			}
+		})
+	}
+}
+
+// tarGz returns a .tar.gz archive of the given files.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipArchive returns a .zip archive of the given files.
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractBinary(t *testing.T) {
+	files := map[string]string{"README.md": "readme", "templui_linux_amd64/templui": "binary", "templui.exe": "windows binary"}
+	tests := []struct {
+		name        string
+		archive     []byte
+		archiveName string
+		binaryName  string
+		want        string
+		wantErr     bool
+	}{
+		{name: "tar.gz", archive: tarGz(t, files), archiveName: "templui_linux_amd64.tar.gz", binaryName: "templui", want: "binary"},
+		{name: "zip", archive: zipArchive(t, files), archiveName: "templui_windows_amd64.zip", binaryName: "templui.exe", want: "windows binary"},
+		{name: "tar.gz without binary", archive: tarGz(t, map[string]string{"README.md": "readme"}), archiveName: "templui_linux_amd64.tar.gz", binaryName: "templui", wantErr: true},
+		{name: "zip without binary", archive: zipArchive(t, map[string]string{"README.md": "readme"}), archiveName: "templui_windows_amd64.zip", binaryName: "templui.exe", wantErr: true},
+		{name: "corrupt archive", archive: []byte("not an archive"), archiveName: "templui_linux_amd64.tar.gz", binaryName: "templui", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractBinary(tt.archive, tt.archiveName, tt.binaryName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractBinary() error = %v, want error %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("extractBinary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// errNotFound is wrapped by registry sources when a requested file does not exist.
+var errNotFound = errors.New("not found")
+
+// registrySource resolves the registry and component files for a given git ref.
+type registrySource interface {
+	// fetch returns the content of a repository-relative path at the given ref.
+	fetch(ref, repoPath string) ([]byte, error)
+	// location describes where a repository-relative path is fetched from (used in logs and errors).
+	location(ref, repoPath string) string
+	// commit resolves a ref to a commit hash, or returns "" if the source can't resolve refs.
+	commit(ref string) (string, error)
+	// latestRelease returns the tag of the latest release, or "" if the source has no releases.
+	latestRelease() (string, error)
+}
+
+// httpSource fetches files from an HTTP base URL laid out like raw.githubusercontent.com.
+// The ref is appended to the base URL, unless the base URL contains a "{ref}" placeholder.
+type httpSource struct {
+	baseURL string
+	headers map[string]string // Sent with every request, e.g., for authentication
+}
+
+func (s httpSource) location(ref, repoPath string) string {
+	if strings.Contains(s.baseURL, "{ref}") {
+		return strings.ReplaceAll(s.baseURL, "{ref}", ref) + repoPath
+	}
+	return s.baseURL + ref + "/" + repoPath
+}
+
+func (s httpSource) fetch(ref, repoPath string) ([]byte, error) {
+	return downloadWithHeaders(s.location(ref, repoPath), s.headers)
+}
+
+// githubRepo returns the GitHub API URL of the repository behind a raw.githubusercontent.com base URL.
+// Only GitHub raw URLs can be mapped to an API that resolves refs and releases.
+func (s httpSource) githubRepo() (string, bool) {
+	repo, ok := strings.CutPrefix(s.baseURL, githubRawHost)
+	if !ok {
+		return "", false
+	}
+	parts := strings.SplitN(repo, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return fmt.Sprintf("https://api.github.com/repos/%s/%s", parts[0], parts[1]), true
+}
+
+func (s httpSource) commit(ref string) (string, error) {
+	repoURL, ok := s.githubRepo()
+	if !ok {
+		return "", nil
+	}
+
+	apiURL := repoURL + "/commits/" + url.PathEscape(ref)
+	headers := map[string]string{"Accept": "application/vnd.github.sha"}
+	maps.Copy(headers, s.headers)
+	resp, body, err := httpClient.get(apiURL, headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to query %s: %w", apiURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve ref via %s: status code %d%s", apiURL, resp.StatusCode, authHint(resp))
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func (s httpSource) latestRelease() (string, error) {
+	repoURL, ok := s.githubRepo()
+	if !ok {
+		return "", nil
+	}
+
+	apiURL := repoURL + "/releases/latest"
+	body, err := downloadWithHeaders(apiURL, s.headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to query latest release: %w", err)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	err = json.Unmarshal(body, &release)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse latest release from %s: %w", apiURL, err)
+	}
+	return release.TagName, nil
+}
+
+// dirSource reads files from a local checkout of the repository.
+// The ref is ignored: the working tree of the directory is used as-is.
+type dirSource struct {
+	root string
+}
+
+func (s dirSource) location(_, repoPath string) string {
+	return filepath.Join(s.root, filepath.FromSlash(repoPath))
+}
+
+func (s dirSource) fetch(ref, repoPath string) ([]byte, error) {
+	if !filepath.IsLocal(filepath.FromSlash(repoPath)) {
+		return nil, fmt.Errorf("invalid file path '%s': must stay inside %s", repoPath, s.root)
+	}
+	path := s.location(ref, repoPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read file %s: %w", path, errNotFound)
+		}
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func (s dirSource) commit(string) (string, error) {
+	// The working tree is used regardless of the ref, so record its HEAD if it is a clean git checkout.
+	// Uncommitted changes aren't part of any commit, so a dirty tree records none.
+	status, err := exec.Command("git", "-C", s.root, "status", "--porcelain").Output()
+	if err != nil || len(bytes.TrimSpace(status)) > 0 {
+		return "", nil
+	}
+	out, err := exec.Command("git", "-C", s.root, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", nil
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s dirSource) latestRelease() (string, error) {
+	return "", nil // The working tree is used regardless of the ref.
+}
+
+// newRegistrySource creates a registry source from a local directory, a file:// URL or an HTTP(S) base URL.
+// An empty value selects the upstream templUI repository on GitHub.
+func newRegistrySource(value string) (registrySource, error) {
+	switch {
+	case value == "":
+		return httpSource{baseURL: rawContentBaseURL}, nil
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		// Repository paths are appended to the base URL, with or without a {ref} placeholder.
+		if !strings.HasSuffix(value, "/") {
+			value += "/"
+		}
+		return httpSource{baseURL: value}, nil
+	case strings.HasPrefix(value, "file://"):
+		u, err := url.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry URL '%s': %w", value, err)
+		}
+		return newDirSource(filepath.FromSlash(u.Host + u.Path))
+	default:
+		return newDirSource(value)
+	}
+}
+
+// newDirSource validates a local registry directory and resolves it to an absolute path,
+// so it stays valid when the working directory changes (e.g., during 'new').
+func newDirSource(dir string) (registrySource, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry directory '%s': %w", dir, err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("registry directory '%s' is not accessible: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("registry path '%s' is not a directory", dir)
+	}
+	return dirSource{root: absDir}, nil
+}
+
+// resolveRegistrySource picks the registry source from the --registry flag,
+// falling back to the "registry" field of .templui.json and then the upstream repository.
+// The "registryHeaders" of .templui.json are sent to HTTP sources either way.
+func resolveRegistrySource(flagValue string) (registrySource, error) {
+	value, headers := readConfiguredRegistry()
+	if flagValue != "" {
+		value = flagValue
+	}
+	src, err := newRegistrySource(value)
+	if err != nil {
+		return nil, err
+	}
+	return withHeaders(src, headers), nil
+}
+
+// withHeaders returns an HTTP source that sends the given headers, with environment variables
+// expanded. Other sources are returned unchanged.
+func withHeaders(src registrySource, headers map[string]string) registrySource {
+	httpSrc, ok := src.(httpSource)
+	if !ok || len(headers) == 0 {
+		return src
+	}
+	httpSrc.headers = make(map[string]string, len(headers))
+	for key, value := range headers {
+		httpSrc.headers[key] = os.ExpandEnv(value)
+	}
+	return httpSrc
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewRegistrySourceHTTP(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "trailing slash", value: "https://mirror.example.com/templui/", want: "https://mirror.example.com/templui/v1.0.0/internal/registry/registry.json"},
+		{name: "no trailing slash", value: "https://mirror.example.com/templui", want: "https://mirror.example.com/templui/v1.0.0/internal/registry/registry.json"},
+		{name: "ref placeholder", value: "https://git.example.com/ui/-/raw/{ref}/", want: "https://git.example.com/ui/-/raw/v1.0.0/internal/registry/registry.json"},
+		{name: "ref placeholder without trailing slash", value: "https://git.example.com/ui/-/raw/{ref}", want: "https://git.example.com/ui/-/raw/v1.0.0/internal/registry/registry.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := newRegistrySource(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := src.location("v1.0.0", registryPath); got != tt.want {
+				t.Errorf("location() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/raw/v1.0.0/internal/components/button/button.templ" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("package button\n"))
+	}))
+	defer server.Close()
+
+	src, err := newRegistrySource(server.URL + "/raw/{ref}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = withHeaders(src, map[string]string{"Authorization": "Bearer secret"})
+
+	data, err := src.fetch("v1.0.0", "internal/components/button/button.templ")
+	if err != nil {
+		t.Fatalf("fetch() error: %v", err)
+	}
+	if string(data) != "package button\n" {
+		t.Errorf("fetch() = %q, want the served file", data)
+	}
+
+	_, err = src.fetch("v1.0.0", "internal/components/missing/missing.templ")
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("fetch() of a missing file error = %v, want errNotFound", err)
+	}
+
+	_, err = withHeaders(httpSource{baseURL: server.URL + "/raw/"}, nil).fetch("v1.0.0", "internal/components/button/button.templ")
+	if err == nil || errors.Is(err, errNotFound) || !strings.Contains(err.Error(), "401") {
+		t.Errorf("fetch() without credentials error = %v, want status code 401", err)
+	}
+}
+
+func TestDirSourceFetch(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"registry/internal/components/button/button.templ": "package button\n",
+		"secret.txt": "secret\n",
+	})
+	src, err := newRegistrySource(filepath.Join(root, "registry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := src.fetch("ignored", "internal/components/button/button.templ")
+	if err != nil || string(data) != "package button\n" {
+		t.Errorf("fetch() = %q, %v, want the file", data, err)
+	}
+	if _, err := src.fetch("ignored", "internal/components/missing.templ"); !errors.Is(err, errNotFound) {
+		t.Errorf("fetch() of a missing file error = %v, want errNotFound", err)
+	}
+	for _, repoPath := range []string{"../secret.txt", "internal/../../secret.txt", filepath.ToSlash(filepath.Join(root, "secret.txt"))} {
+		if data, err := src.fetch("ignored", repoPath); err == nil || errors.Is(err, errNotFound) {
+			t.Errorf("fetch(%q) = %q, %v, want an invalid path error", repoPath, data, err)
+		}
+	}
+}
+
+func TestDirSourceCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"internal/registry/registry.json": "{}\n"})
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	head := git("rev-parse", "HEAD")
+
+	src := dirSource{root: root}
+	if commit, err := src.commit("ignored"); err != nil || commit != head {
+		t.Errorf("commit() of a clean checkout = %q, %v, want %s", commit, err, head)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "internal/registry/registry.json"), []byte("{ }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if commit, err := src.commit("ignored"); err != nil || commit != "" {
+		t.Errorf("commit() of a dirty checkout = %q, %v, want none", commit, err)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// JavaScript states of an installed component.
+const (
+	jsPresent = "present" // The JavaScript file exists in the JS directory
+	jsMissing = "missing" // The component needs JavaScript, but its file doesn't exist
+)
+
+// statusEntry reports the state of an installed component or util.
+type statusEntry struct {
+	Kind  string `json:"kind"`         // "component" or "util"
+	Name  string `json:"name"`         // Component name or util path
+	Ref   string `json:"ref"`          // Ref from the version comment of the installed files, "" if unknown
+	State string `json:"state"`        // Worst verification state of its files, see verifyFile
+	JS    string `json:"js,omitempty"` // jsPresent or jsMissing for components with JavaScript
+}
+
+// installedUtil is a util file installed in the project.
+type installedUtil struct {
+	repoPath string      // Path relative to the repository root
+	path     string      // Path in the project
+	locked   *LockedUtil // nil if the util is not in the lockfile
+}
+
+// runStatus handles the 'status' command logic.
+func runStatus(args []string) int {
+	config, err := loadConfig()
+	if err != nil {
+		failf("Error loading config: %v\n", err)
+		return exitError
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return exitError
+	}
+
+	// Without explicit components, show everything that is installed, including utils.
+	componentNames := args
+	showUtils := len(componentNames) == 0
+	if showUtils {
+		componentNames, err = getInstalledComponentNames(config, lock)
+		if err != nil {
+			failf("Error detecting installed components: %v\n", err)
+			return exitError
+		}
+	}
+
+	exitCode := exitOK
+	registryAt := registryLookup()
+	var entries []statusEntry
+	for _, componentName := range componentNames {
+		fileResults, err := verifyComponent(config, lock, componentName, registryAt)
+		if err != nil {
+			failf("Error checking component '%s': %v\n", componentName, err)
+			exitCode = exitError
+			continue
+		}
+		entry := statusEntry{Kind: "component", Name: componentName, State: verifyOK}
+		var states []string
+		for _, r := range fileResults {
+			if r.Kind == "js" {
+				entry.JS = jsPresent
+				if r.Status == verifyMissing {
+					entry.JS = jsMissing
+				}
+				continue
+			}
+			if entry.Ref == "" {
+				entry.Ref, _ = readFileVersion(filepath.FromSlash(r.Path))
+			}
+			states = append(states, r.Status)
+		}
+		entry.State = worstState(states)
+		if entry.JS == "" && config.JSDir != "" {
+			entry.JS = componentJSState(config, lock, componentName, registryAt)
+		}
+		entries = append(entries, entry)
+	}
+
+	if showUtils {
+		utils, err := installedUtils(config, lock)
+		if err != nil {
+			failf("Error detecting installed utils: %v\n", err)
+			return exitError
+		}
+		for _, util := range utils {
+			entry := statusEntry{Kind: "util", Name: util.repoPath, State: verifyUnverified}
+			entry.Ref, _ = readFileVersion(filepath.FromSlash(util.path))
+			if util.locked != nil {
+				published := ""
+				if registry := registryAt(util.repoPath, util.locked.Ref); registry != nil {
+					published = registry.FileHashes()[util.repoPath]
+				}
+				entry.State = verifyFile(util.path, util.locked.SHA256, published, func(data []byte) []byte {
+					return normalizeInstalledUtil(data, config, util.repoPath)
+				})
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	result.Status = entries
+	printStatus(entries)
+	return exitCode
+}
+
+// componentJSState checks the JavaScript file of a component whose JavaScript isn't in the lockfile,
+// e.g. because it was missing at installation. It returns "" if the component doesn't need JavaScript.
+func componentJSState(config Config, lock *Lockfile, componentName string, registryAt func(name, ref string) *Registry) string {
+	ref, err := installedComponentRef(config, lock, componentName)
+	if err != nil {
+		return ""
+	}
+	registry := registryAt(componentName, ref)
+	if registry == nil {
+		return ""
+	}
+	comp, ok := findComponent(*registry, componentName)
+	if !ok || !comp.HasJS {
+		return ""
+	}
+	if _, err := os.Stat(componentJSPath(config, componentName)); err != nil {
+		return jsMissing
+	}
+	return jsPresent
+}
+
+// worstState returns the most severe of the verification states of a component's files.
+func worstState(states []string) string {
+	for _, state := range []string{verifyMissing, verifyTampered, verifyModified, verifyUnverified} {
+		if slices.Contains(states, state) {
+			return state
+		}
+	}
+	return verifyOK
+}
+
+// installedUtils returns the utils recorded in the lockfile, followed by other files in the
+// utils directory that carry a templUI version comment.
+func installedUtils(config Config, lock *Lockfile) ([]installedUtil, error) {
+	var utils []installedUtil
+	lockedPaths := make(map[string]bool)
+	for _, repoUtilPath := range lock.utilPaths() {
+		locked := lock.Utils[repoUtilPath]
+		utils = append(utils, installedUtil{repoPath: repoUtilPath, path: locked.Path, locked: &locked})
+		lockedPaths[filepath.Clean(filepath.FromSlash(locked.Path))] = true
+	}
+
+	err := filepath.WalkDir(config.UtilsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == config.UtilsDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || lockedPaths[filepath.Clean(path)] {
+			return nil
+		}
+		ref, err := readFileVersion(path)
+		if err != nil || ref == "" {
+			return err
+		}
+		relativePath, err := filepath.Rel(config.UtilsDir, path)
+		if err != nil {
+			return err
+		}
+		utils = append(utils, installedUtil{repoPath: utilRepoPath(filepath.ToSlash(relativePath)), path: filepath.ToSlash(path)})
+		return nil
+	})
+	return utils, err
+}
+
+// printStatus prints the status entries as a table.
+func printStatus(entries []statusEntry) {
+	if len(entries) == 0 {
+		fmt.Println("No components or utils installed.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tREF\tSTATE\tJS")
+	for _, e := range entries {
+		ref, js := e.Ref, e.JS
+		if ref == "" {
+			ref = "?"
+		}
+		if js == "" {
+			js = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Kind, e.Name, ref, e.State, js)
+	}
+	w.Flush()
+
+	for _, e := range entries {
+		if e.State == verifyModified {
+			fmt.Println("\n💡 Run 'templui diff' to see local modifications.")
+			break
+		}
+	}
+	for _, e := range entries {
+		if e.JS == jsMissing {
+			fmt.Println("💡 Run 'templui add --force <component>' to restore missing JavaScript files.")
+			break
+		}
+	}
+}
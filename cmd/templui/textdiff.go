@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffOp is the kind of a line in an edit script.
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+// diffEdit is a single line of an edit script.
+type diffEdit struct {
+	op   diffOp
+	line string
+}
+
+// diffContextLines is the number of unchanged lines shown around each change in a unified diff.
+const diffContextLines = 3
+
+// splitLines splits text into lines, keeping the line terminators.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a minimal line-based edit script turning a into b.
+func diffLines(a, b []string) []diffEdit {
+	// Strip the common prefix and suffix, which keeps the search small for typical edits.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, diffEdit{opEqual, line})
+	}
+	edits = append(edits, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, diffEdit{opEqual, line})
+	}
+	return edits
+}
+
+// myersDiff implements Myers' O(ND) difference algorithm.
+func myersDiff(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] holds the furthest reaching x per diagonal k in [-d, d] before round d.
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Step down (insertion).
+			} else {
+				x = v[offset+k-1] + 1 // Step right (deletion).
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b)
+			}
+		}
+	}
+	return nil // Unreachable: a path is always found for d <= n+m.
+}
+
+// backtrackDiff walks the recorded trace backwards to build the edit script.
+func backtrackDiff(trace [][]int, a, b []string) []diffEdit {
+	x, y := len(a), len(b)
+	var reversed []diffEdit
+	for d := len(trace) - 1; d >= 0; d-- {
+		if d == 0 {
+			for x > 0 {
+				reversed = append(reversed, diffEdit{opEqual, a[x-1]})
+				x--
+			}
+			break
+		}
+
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffEdit{opEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, diffEdit{opInsert, b[y-1]})
+		} else {
+			reversed = append(reversed, diffEdit{opDelete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]diffEdit, len(reversed))
+	for i, edit := range reversed {
+		edits[len(reversed)-1-i] = edit
+	}
+	return edits
+}
+
+// unifiedDiff renders a unified diff from a to b, or returns "" if both are identical.
+func unifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	// Line positions in a and b before each edit.
+	aPos := make([]int, len(edits)+1)
+	bPos := make([]int, len(edits)+1)
+	for i, edit := range edits {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if edit.op != opInsert {
+			aPos[i+1]++
+		}
+		if edit.op != opDelete {
+			bPos[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == opEqual {
+			i++
+			continue
+		}
+
+		// Extend the hunk while changes are close enough to share context.
+		start := max(0, i-diffContextLines)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != opEqual {
+				end = j + 1
+			} else if j-end >= 2*diffContextLines {
+				break
+			}
+		}
+		end = min(len(edits), end+diffContextLines)
+
+		aStart, aLen := aPos[start], aPos[end]-aPos[start]
+		bStart, bLen := bPos[start], bPos[end]-bPos[start]
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, edit := range edits[start:end] {
+			prefix := " "
+			switch edit.op {
+			case opDelete:
+				prefix = "-"
+			case opInsert:
+				prefix = "+"
+			}
+			out.WriteString(prefix + edit.line)
+			if !strings.HasSuffix(edit.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the line range of a hunk header.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start) // Empty ranges refer to the line before.
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
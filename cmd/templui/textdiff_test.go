@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns the lines "1\n" to "<n>\n", replacing the given line numbers.
+func numberedLines(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			b.WriteString(line + "\n")
+		} else {
+			fmt.Fprintf(&b, "%d\n", i)
+		}
+	}
+	return b.String()
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		changes int // Deleted plus inserted lines of a minimal edit script.
+	}{
+		{"identical", "a\nb\nc\n", "a\nb\nc\n", 0},
+		{"both empty", "", "", 0},
+		{"insert into empty", "", "a\nb\n", 2},
+		{"delete all", "a\nb\n", "", 2},
+		{"replace line", "a\nb\nc\n", "a\nx\nc\n", 2},
+		{"insert in middle", "a\nc\n", "a\nb\nc\n", 1},
+		{"delete in middle", "a\nb\nc\n", "a\nc\n", 1},
+		{"move line", "a\nb\nc\nd\n", "b\nc\nd\na\n", 2},
+		{"interleaved", "a\nb\nc\nd\ne\n", "x\nb\ny\nd\nz\n", 6},
+		{"missing trailing newline", "a\nb", "a\nb\n", 2},
+		{"repeated lines", "a\na\nb\na\n", "a\nb\na\na\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := diffLines(splitLines(tt.a), splitLines(tt.b))
+
+			var from, to strings.Builder
+			changes := 0
+			for _, edit := range edits {
+				if edit.op != opInsert {
+					from.WriteString(edit.line)
+				}
+				if edit.op != opDelete {
+					to.WriteString(edit.line)
+				}
+				if edit.op != opEqual {
+					changes++
+				}
+			}
+			if from.String() != tt.a {
+				t.Errorf("edit script doesn't start from a: got %q, want %q", from.String(), tt.a)
+			}
+			if to.String() != tt.b {
+				t.Errorf("edit script doesn't produce b: got %q, want %q", to.String(), tt.b)
+			}
+			if changes != tt.changes {
+				t.Errorf("got %d changed lines, want %d", changes, tt.changes)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "replace line",
+			a:    "a\nb\nc\n",
+			b:    "a\nx\nc\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "insert into empty file",
+			a:    "",
+			b:    "x\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			name: "delete whole file",
+			a:    "x\n",
+			b:    "",
+			want: "--- a\n+++ b\n@@ -1 +0,0 @@\n-x\n",
+		},
+		{
+			name: "insert after context",
+			a:    numberedLines(10, nil),
+			b:    strings.Replace(numberedLines(10, nil), "5\n", "5\nnew\n", 1),
+			want: "--- a\n+++ b\n@@ -3,6 +3,7 @@\n 3\n 4\n 5\n+new\n 6\n 7\n 8\n",
+		},
+		{
+			name: "distant changes in separate hunks",
+			a:    numberedLines(20, nil),
+			b:    numberedLines(20, map[int]string{2: "two", 19: "nineteen"}),
+			want: "--- a\n+++ b\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -16,5 +16,5 @@\n 16\n 17\n 18\n-19\n+nineteen\n 20\n",
+		},
+		{
+			name: "close changes share a hunk",
+			a:    numberedLines(12, nil),
+			b:    numberedLines(12, map[int]string{3: "three", 9: "nine"}),
+			want: "--- a\n+++ b\n@@ -1,12 +1,12 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n 8\n-9\n+nine\n 10\n 11\n 12\n",
+		},
+		{
+			name: "missing trailing newline",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff("a", "b", tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
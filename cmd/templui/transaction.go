@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stagingDirPattern names the temporary directory in the project that holds staged files and backups.
+// It is created next to the config so that staged files can be renamed into place.
+const stagingDirPattern = ".templui-staging-*"
+
+// installTransaction stages the files written by an installation, so that they are either
+// all moved into place or, if anything fails, none of them are.
+type installTransaction struct {
+	keepBackups bool // Keep the replaced files as <file>.orig
+	dir         string
+	writes      []*stagedWrite
+}
+
+// stagedWrite is a single file waiting to be moved into place.
+type stagedWrite struct {
+	destPath   string
+	stagedPath string
+	backupPath string // Where the replaced file was moved during commit, "" if there was none
+	createdDir string // Outermost directory created for the file during commit, "" if none
+	applied    bool
+}
+
+// newInstallTransaction returns an empty transaction. The staging directory is created on the first write.
+func newInstallTransaction(keepBackups bool) *installTransaction {
+	return &installTransaction{keepBackups: keepBackups}
+}
+
+// stage stores the new content of a destination file. Staging the same path again replaces its content.
+func (t *installTransaction) stage(destPath string, data []byte) error {
+	if t.dir == "" {
+		dir, err := os.MkdirTemp(".", stagingDirPattern)
+		if err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		t.dir = dir
+	}
+
+	w := t.find(destPath)
+	if w == nil {
+		w = &stagedWrite{destPath: destPath, stagedPath: filepath.Join(t.dir, fmt.Sprintf("%d", len(t.writes)))}
+		t.writes = append(t.writes, w)
+	}
+	err := os.WriteFile(w.stagedPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to stage file '%s': %w", destPath, err)
+	}
+	return nil
+}
+
+// staged returns the staged content of a destination file, if any.
+func (t *installTransaction) staged(destPath string) ([]byte, bool) {
+	w := t.find(destPath)
+	if w == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(w.stagedPath)
+	return data, err == nil
+}
+
+// find returns the staged write of a destination file, or nil.
+func (t *installTransaction) find(destPath string) *stagedWrite {
+	for _, w := range t.writes {
+		if w.destPath == destPath {
+			return w
+		}
+	}
+	return nil
+}
+
+// commit moves all staged files into place. Replaced files are moved aside first, so that
+// on failure every file already moved is rolled back and the project is left unchanged.
+func (t *installTransaction) commit() error {
+	defer t.discard()
+	if len(t.writes) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n💾 Writing %d file(s)...\n", len(t.writes))
+	for i, w := range t.writes {
+		err := t.apply(w, i)
+		if err != nil {
+			t.rollback()
+			return fmt.Errorf("%w; all changes were rolled back", err)
+		}
+	}
+
+	for _, w := range t.writes {
+		if w.backupPath == "" {
+			fmt.Printf("      ✅ Installed %s\n", w.destPath)
+			continue
+		}
+		fmt.Printf("      ✅ Overwritten %s\n", w.destPath)
+		if t.keepBackups {
+			err := os.Rename(w.backupPath, w.destPath+".orig")
+			if err != nil {
+				fmt.Printf("      ⚠️  Could not keep backup of '%s': %v\n", w.destPath, err)
+				continue
+			}
+			fmt.Printf("      🗂️  Kept previous version as %s.orig\n", w.destPath)
+		}
+	}
+	return nil
+}
+
+// apply moves a single staged file into place, backing up the file it replaces.
+func (t *installTransaction) apply(w *stagedWrite, i int) error {
+	destDir := filepath.Dir(w.destPath)
+	w.createdDir = outermostMissingDir(destDir)
+	err := os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create destination directory '%s': %w", destDir, err)
+	}
+
+	if _, err := os.Stat(w.destPath); err == nil {
+		backupPath := filepath.Join(t.dir, fmt.Sprintf("%d.backup", i))
+		err = os.Rename(w.destPath, backupPath)
+		if err != nil {
+			return fmt.Errorf("failed to back up file '%s': %w", w.destPath, err)
+		}
+		w.backupPath = backupPath
+	}
+
+	err = os.Rename(w.stagedPath, w.destPath)
+	if err != nil {
+		return fmt.Errorf("failed to write file '%s': %w", w.destPath, err)
+	}
+	w.applied = true
+	return nil
+}
+
+// rollback restores the files replaced so far, removes newly created files and the directories
+// created for them if they are empty, and reports anything that could not be restored.
+func (t *installTransaction) rollback() {
+	for i := len(t.writes) - 1; i >= 0; i-- {
+		w := t.writes[i]
+		if w.applied {
+			os.Remove(w.destPath)
+		}
+		if w.backupPath != "" {
+			err := os.Rename(w.backupPath, w.destPath)
+			if err != nil {
+				fmt.Printf("      ⚠️  Could not restore '%s' (backup kept at %s): %v\n", w.destPath, w.backupPath, err)
+				t.dir = "" // Don't delete the backup.
+			}
+		}
+		if w.createdDir != "" {
+			removeEmptyDirs(filepath.Dir(w.destPath), w.createdDir)
+		}
+	}
+}
+
+// removeEmptyDirs removes dir and its parents up to top, stopping at the first directory that isn't empty,
+// so files that were added to a created directory in the meantime are kept.
+func removeEmptyDirs(dir, top string) {
+	for os.Remove(dir) == nil && dir != top {
+		dir = filepath.Dir(dir)
+	}
+}
+
+// discard removes the staging directory with everything that was not moved into place.
+func (t *installTransaction) discard() {
+	if t.dir != "" {
+		os.RemoveAll(t.dir)
+		t.dir = ""
+	}
+}
+
+// outermostMissingDir returns the outermost directory of path that doesn't exist yet, or "" if path exists.
+func outermostMissingDir(path string) string {
+	missing := ""
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return missing
+		}
+		missing = path
+		parent := filepath.Dir(path)
+		if parent == path {
+			return missing
+		}
+		path = parent
+	}
+}
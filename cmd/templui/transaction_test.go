@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFile returns the content of a file, or "" if it can't be read.
+func readFile(path string) string {
+	data, _ := os.ReadFile(filepath.FromSlash(path))
+	return string(data)
+}
+
+// assertNoStagingDir fails the test if a staging directory was left in the working directory.
+func assertNoStagingDir(t *testing.T) {
+	t.Helper()
+	if dirs, _ := filepath.Glob(stagingDirPattern); len(dirs) > 0 {
+		t.Errorf("staging directories left behind: %v", dirs)
+	}
+}
+
+func TestInstallFetchFailureChangesNothing(t *testing.T) {
+	const buttonPath = "internal/components/button/button.templ"
+	server := newRefServer(t, map[string]map[string]string{
+		"v1": {buttonPath: "package button\n"},
+		"v2": {buttonPath: "package button\n\n// v2\n"}, // card.templ is missing at v2
+	})
+	useSource(t, httpSource{baseURL: server.URL + "/"})
+	config, lock := newTestProject(t)
+	button := ComponentDef{Name: "button", Files: []string{buttonPath}}
+	card := ComponentDef{Name: "card", Files: []string{"internal/components/card/card.templ"}}
+	if err := install(config, "v1", []ComponentDef{button}, nil, &installOptions{lock: lock}); err != nil {
+		t.Fatalf("install v1: %v", err)
+	}
+	before := readFile("components/button/button.templ")
+
+	err := install(config, "v2", []ComponentDef{button, card}, nil, &installOptions{force: true, lock: lock})
+	if err == nil {
+		t.Fatal("install with a missing file succeeded")
+	}
+	if got := readFile("components/button/button.templ"); got != before {
+		t.Errorf("existing file was changed by a failed install:\n%s", got)
+	}
+	if exists("components/card") {
+		t.Error("failed install created the directory of a new component")
+	}
+	if lock.Components["button"].Ref != "v1" {
+		t.Errorf("failed install locked button at %s", lock.Components["button"].Ref)
+	}
+	assertNoStagingDir(t)
+}
+
+func TestInstallTransactionRollback(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTree(t, ".", map[string]string{
+		"components/button/button.templ": "old button\n",
+		"blocker":                        "a file where a directory is needed\n",
+	})
+
+	tx := newInstallTransaction(false)
+	// The last write fails, after the others were moved into place.
+	for _, write := range []struct{ path, content string }{
+		{"components/button/button.templ", "new button\n"},
+		{"components/card/card.templ", "new card\n"},
+		{"blocker/fail.templ", "can't be written\n"},
+	} {
+		if err := tx.stage(filepath.FromSlash(write.path), []byte(write.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tx.commit(); err == nil {
+		t.Fatal("commit with an unwritable file succeeded")
+	}
+	if got := readFile("components/button/button.templ"); got != "old button\n" {
+		t.Errorf("replaced file wasn't restored, got %q", got)
+	}
+	if exists("components/card") {
+		t.Error("directory created for a rolled back file is left behind")
+	}
+	assertNoStagingDir(t)
+}
+
+func TestInstallTransactionRollbackKeepsOtherFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tx := newInstallTransaction(false)
+	destPath := filepath.Join("components", "card", "card.templ")
+	if err := tx.stage(destPath, []byte("new card\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.apply(tx.writes[0], 0); err != nil {
+		t.Fatal(err)
+	}
+	// A file added to the created directory before the rollback is not part of the transaction.
+	writeTree(t, ".", map[string]string{"components/card/notes.md": "Our notes\n"})
+
+	tx.rollback()
+	tx.discard()
+	if exists("components/card/card.templ") {
+		t.Error("rolled back file is left behind")
+	}
+	if !exists("components/card/notes.md") {
+		t.Error("rollback deleted a file that isn't part of the transaction")
+	}
+	assertNoStagingDir(t)
+}
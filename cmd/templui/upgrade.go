@@ -7,31 +7,73 @@ import (
 	"strings"
 )
 
-// runUpgrade handles the 'upgrade' command logic.
-func runUpgrade(args []string, commandArg string) {
-	var ref string
-
-	if strings.Contains(commandArg, "@") {
-		parts := strings.SplitN(commandArg, "@", 2)
-		if len(parts) == 2 && parts[0] == "upgrade" && parts[1] != "" {
-			ref = parts[1]
-			fmt.Printf("Updating templUI using specified ref: %s\n", ref)
-		} else {
-			fmt.Printf("Error: Invalid format '%s'. Use 'upgrade' or 'upgrade@<ref>'.\n", commandArg)
-			return
-		}
+// cliPackage is the Go package path of the templUI CLI.
+const cliPackage = "github.com/templui/templui/cmd/templui"
+
+// runUpgrade handles the 'upgrade' command logic. The CLI is replaced with the release binary
+// for the current platform, or built with 'go install' if goInstall is set or ref isn't a release tag.
+func runUpgrade(args []string, ref string, dryRun, keepBackups, goInstall bool, releaseURL string) {
+	if ref != "" {
+		fmt.Printf("Updating templUI using specified ref: %s\n", ref)
 	}
 
+	result.Ref = ref
+
 	// Step 1: Update the CLI
-	if err := updateCLI(ref); err != nil {
-		fmt.Printf("Error upgrading templUI CLI: %v\n", err)
+	cliAction, err := upgradeCLI(ref, dryRun, goInstall, releaseURL)
+	if err != nil {
+		failf("Error upgrading templUI CLI: %v\n", err)
 		return
 	}
+	result.Actions = append(result.Actions, cliAction)
+
+	// Step 2: Update utils (only if config exists) to the release the CLI was upgraded to
+	if err := updateUtils(cliAction.Ref, dryRun, keepBackups); err != nil {
+		failf("Error updating utils: %v\n", err)
+	}
+}
+
+// upgradeCLI replaces the CLI with the release binary of ref, or builds it with 'go install'.
+// Only release tags have binaries, so branches and commits are always built with 'go install'.
+func upgradeCLI(ref string, dryRun, goInstall bool, releaseURL string) (fileAction, error) {
+	cliAction := fileAction{Kind: "cli", Name: "templui", Path: cliPackage, Action: actionInstall, Ref: ref}
+	if cliAction.Ref == "" {
+		cliAction.Ref = "latest"
+	}
+	if !goInstall && cliAction.Ref != "latest" && !semverTagRegex.MatchString(ref) {
+		fmt.Printf("'%s' is not a release tag, building the CLI with 'go install' instead.\n", ref)
+		goInstall = true
+	}
+
+	if goInstall {
+		if dryRun {
+			fmt.Printf("Would run: go install %s@%s\n", cliPackage, cliAction.Ref)
+			return cliAction, nil
+		}
+		return cliAction, updateCLI(ref)
+	}
 
-	// Step 2: Update utils (only if config exists)
-	if err := updateUtils(ref); err != nil {
-		fmt.Printf("Error updating utils: %v\n", err)
+	base := releaseBaseURL(releaseURL)
+	tag, err := resolveReleaseTag(ref, base, source)
+	if err != nil {
+		return cliAction, fmt.Errorf("failed to find the latest release (pass one with 'templui upgrade@<tag>', or use --go-install): %w", err)
+	}
+	cliAction.Ref = tag
+	if tag == version {
+		fmt.Printf("✅ templUI CLI is already at %s\n", version)
+		cliAction.Action = actionSkip
+		return cliAction, nil
+	}
+	fmt.Printf("Updating templUI CLI to release '%s'...\n", tag)
+	exe, err := selfUpdate(base, tag, dryRun)
+	if err != nil {
+		return cliAction, err
 	}
+	cliAction.Path = exe
+	if !dryRun {
+		fmt.Printf("✅ Updated templUI CLI at %s to '%s'\n", exe, tag)
+	}
+	return cliAction, nil
 }
 
 // updateCLI attempts to install a new version of the templUI cli based on the passed in ref.
@@ -40,7 +82,7 @@ func updateCLI(ref string) error {
 		ref = "latest"
 	}
 	fmt.Printf("Updating templUI CLI to ref '%s'...\n", ref)
-	cmd := exec.Command("go", "install", fmt.Sprintf("github.com/templui/templui/cmd/templui@%s", ref))
+	cmd := exec.Command("go", "install", fmt.Sprintf("%s@%s", cliPackage, ref))
 	output, err := cmd.Output()
 	if err != nil {
 		return err
@@ -52,7 +94,8 @@ func updateCLI(ref string) error {
 }
 
 // updateUtils updates all utils from the registry to the configured utils directory.
-func updateUtils(ref string) error {
+// In dry-run mode it only prints the planned changes.
+func updateUtils(ref string, dryRun bool, keepBackups bool) error {
 	// Check if config exists
 	if _, err := os.Stat(configFileName); os.IsNotExist(err) {
 		fmt.Println("No config file found. Skipping utils update. Run 'templui init' first to set up your project.")
@@ -85,18 +128,49 @@ func updateUtils(ref string) error {
 		return nil
 	}
 
-	// Collect all util paths
+	lock, err := loadLockfile()
+	if err != nil {
+		return err
+	}
+
+	// Collect util paths: the locked ones if the lockfile records any, otherwise all from the registry
 	allUtilPaths := []string{}
 	for _, utilDef := range registry.Utils {
-		allUtilPaths = append(allUtilPaths, utilDef.Path)
+		if _, locked := lock.Utils[utilDef.Path]; locked || len(lock.Utils) == 0 {
+			allUtilPaths = append(allUtilPaths, utilDef.Path)
+		}
 	}
 
 	// Install utils with force=true to ensure they get updated
-	err = installUtils(config, allUtilPaths, utilsRef, true)
+	opts := &installOptions{force: true, dryRun: dryRun, keepBackups: keepBackups, lock: lock, hashes: registry.FileHashes()}
+	err = install(config, utilsRef, nil, allUtilPaths, opts)
+	recordInstall(opts)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		printInstallPlan(opts.actions)
+		return nil
+	}
+	lock.markUtilsExplicit(allUtilPaths)
+
+	err = saveLockfile(lock)
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("✅ Utils updated successfully.")
+
+	// Point out locked components that are still on a different ref
+	var behind []string
+	for _, name := range lock.componentNames() {
+		if lock.Components[name].Ref != utilsRef {
+			behind = append(behind, fmt.Sprintf("%s (%s)", name, lock.Components[name].Ref))
+		}
+	}
+	if len(behind) > 0 {
+		fmt.Printf("\nComponents installed from other refs: %s\n", strings.Join(behind, ", "))
+		fmt.Printf("Run 'templui add@%s --installed' to update them as well.\n", utilsRef)
+	}
 	return nil
 }
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Exit codes of the 'verify' command.
+const (
+	verifyExitOK       = 0
+	verifyExitMismatch = 1
+	verifyExitError    = 2
+)
+
+// Verification states of an installed file.
+const (
+	verifyOK         = "ok"         // Matches the lockfile and, if published, the registry hash
+	verifyModified   = "modified"   // Edited locally since installation
+	verifyTampered   = "tampered"   // Unchanged since installation, but doesn't match the published hash
+	verifyMissing    = "missing"    // Installed file no longer exists
+	verifyUnverified = "unverified" // Neither a lockfile entry nor a published hash is available
+)
+
+// verifyResult reports the verification state of a single installed file.
+type verifyResult struct {
+	Kind   string `json:"kind"` // "component", "js" or "util"
+	Name   string `json:"name"` // Component name or util path
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// runVerify handles the 'verify' command logic and returns the exit code.
+func runVerify(args []string) int {
+	config, err := loadConfig()
+	if err != nil {
+		failf("Error loading config: %v\n", err)
+		return verifyExitError
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return verifyExitError
+	}
+
+	// Without explicit components, verify everything that is installed, including utils.
+	componentNames := args
+	verifyUtils := len(componentNames) == 0
+	if verifyUtils {
+		componentNames, err = getInstalledComponentNames(config, lock)
+		if err != nil {
+			failf("Error detecting installed components: %v\n", err)
+			return verifyExitError
+		}
+	}
+
+	registryAt := registryLookup()
+	exitCode := verifyExitOK
+	var results []verifyResult
+	for _, componentName := range componentNames {
+		componentResults, err := verifyComponent(config, lock, componentName, registryAt)
+		if err != nil {
+			failf("Error verifying component '%s': %v\n", componentName, err)
+			exitCode = verifyExitError
+			continue
+		}
+		results = append(results, componentResults...)
+	}
+	if verifyUtils {
+		for _, repoUtilPath := range lock.utilPaths() {
+			locked := lock.Utils[repoUtilPath]
+			published := ""
+			if registry := registryAt(repoUtilPath, locked.Ref); registry != nil {
+				published = registry.FileHashes()[repoUtilPath]
+			}
+			status := verifyFile(locked.Path, locked.SHA256, published, func(data []byte) []byte {
+				return normalizeInstalledUtil(data, config, repoUtilPath)
+			})
+			results = append(results, verifyResult{Kind: "util", Name: repoUtilPath, Path: locked.Path, Status: status})
+		}
+	}
+
+	result.Verified = results
+	printVerifyResults(results)
+	for _, r := range results {
+		if r.Status != verifyOK && r.Status != verifyUnverified && exitCode == verifyExitOK {
+			exitCode = verifyExitMismatch
+		}
+	}
+	return exitCode
+}
+
+// registryLookup returns a function that fetches the registry a component or repository path comes from
+// at a ref once, and returns nil if it is unavailable, in which case installed files are only checked against the lockfile.
+func registryLookup() func(name, ref string) *Registry {
+	registries := make(map[string]*Registry) // Fetched registries by registry name and ref, nil if unavailable.
+	return func(name, ref string) *Registry {
+		scope, _ := splitScope(name)
+		key := qualifiedName(scope, ref)
+		if registry, ok := registries[key]; ok {
+			return registry
+		}
+		registry, err := fetchRegistryFor(name, ref)
+		if err != nil {
+			fmt.Printf("⚠️  Could not fetch registry for ref '%s', only checking against %s: %v\n", ref, lockFileName, err)
+			registries[key] = nil
+			return nil
+		}
+		registries[key] = &registry
+		return &registry
+	}
+}
+
+// verifyComponent verifies the files of an installed component against the lockfile and the
+// hashes published in the registry of the ref it was installed from.
+func verifyComponent(config Config, lock *Lockfile, componentName string, registryAt func(name, ref string) *Registry) ([]verifyResult, error) {
+	locked, isLocked := lock.Components[componentName]
+	ref := locked.Ref
+	if !isLocked {
+		var err error
+		ref, err = installedComponentRef(config, lock, componentName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var comp ComponentDef
+	registry := registryAt(componentName, ref)
+	if registry != nil {
+		comp, _ = findComponent(*registry, componentName)
+	}
+	if !isLocked && comp.Name == "" {
+		return nil, fmt.Errorf("component '%s' is neither in %s nor in the registry for ref '%s'", componentName, lockFileName, ref)
+	}
+
+	// Collect the installed files with their lockfile hashes and repository paths.
+	type installedFile struct {
+		kind, path, lockedSHA, repoPath string
+	}
+	var files []installedFile
+	if isLocked {
+		for i, file := range locked.Files {
+			repoPath := ""
+			if i < len(comp.Files) {
+				repoPath = comp.Files[i]
+			}
+			files = append(files, installedFile{"component", file.Path, file.SHA256, repoPath})
+		}
+		if locked.JS != nil {
+			files = append(files, installedFile{"js", locked.JS.Path, locked.JS.SHA256, componentJSRepoPath(componentName)})
+		}
+	} else {
+		for _, repoFilePath := range comp.Files {
+			destPath, _ := componentDestPath(config, repoFilePath)
+			files = append(files, installedFile{"component", filepath.ToSlash(destPath), "", repoFilePath})
+		}
+		if comp.HasJS && config.JSDir != "" {
+			files = append(files, installedFile{"js", filepath.ToSlash(componentJSPath(config, comp.Name)), "", componentJSRepoPath(comp.Name)})
+		}
+	}
+
+	var results []verifyResult
+	for _, file := range files {
+		normalize := func(data []byte) []byte { return data } // JavaScript files are installed unchanged.
+		if file.kind == "component" {
+			normalize = func(data []byte) []byte { return normalizeInstalledComponentFile(data, config, comp) }
+		}
+		status := verifyFile(file.path, file.lockedSHA, comp.Hashes[file.repoPath], normalize)
+		results = append(results, verifyResult{Kind: file.kind, Name: componentName, Path: file.path, Status: status})
+	}
+	return results, nil
+}
+
+// verifyFile re-hashes an installed file. Local edits are detected with the hash recorded in the
+// lockfile, while the published hash is compared with the file minus the changes made at installation.
+func verifyFile(path, lockedSHA, publishedSHA string, normalize func([]byte) []byte) string {
+	data, err := os.ReadFile(filepath.FromSlash(path))
+	if err != nil {
+		return verifyMissing
+	}
+	modified := lockedSHA != "" && hashContent(data) != lockedSHA
+
+	switch {
+	case modified:
+		return verifyModified
+	case publishedSHA != "" && hashContent(normalize(data)) != publishedSHA:
+		return verifyTampered
+	case publishedSHA != "" || lockedSHA != "":
+		return verifyOK
+	default:
+		return verifyUnverified
+	}
+}
+
+// normalizeInstalledComponentFile undoes what an installation changed in a component file:
+// the header comments, the rewritten import paths, the package name and the appended Script() template.
+func normalizeInstalledComponentFile(data []byte, config Config, comp ComponentDef) []byte {
+	data = restoreImports(stripHeader(data), config)
+	data, _ = renamePackage(data, config.componentPackage(comp.Name), upstreamPackageName(comp.Name))
+	if comp.HasJS && config.JSDir != "" {
+		withScript, _ := withScriptTemplate([]byte("x"), config, componentJSFile(comp.Name))
+		scriptSuffix := withScript[1:]
+		if trimmed, ok := bytes.CutSuffix(data, scriptSuffix); ok {
+			data = append(trimmed, '\n')
+		}
+	}
+	return data
+}
+
+// normalizeInstalledUtil undoes what an installation changed in a util file:
+// the header comment, the rewritten import paths and the package name.
+func normalizeInstalledUtil(data []byte, config Config, repoUtilPath string) []byte {
+	data = restoreImports(stripHeader(data), config)
+	data, _ = renamePackage(data, utilPackageName(config, repoUtilPath), "utils")
+	return data
+}
+
+// printVerifyResults prints the verification state of each file and a summary.
+func printVerifyResults(results []verifyResult) {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+		switch r.Status {
+		case verifyOK:
+			fmt.Printf("✅ %s\n", r.Path)
+		case verifyModified:
+			fmt.Printf("✏️  %s (modified locally)\n", r.Path)
+		case verifyTampered:
+			fmt.Printf("❌ %s (does not match the hash published in the registry)\n", r.Path)
+		case verifyMissing:
+			fmt.Printf("❌ %s (missing)\n", r.Path)
+		case verifyUnverified:
+			fmt.Printf("❔ %s (unverified: not in %s and no published hash)\n", r.Path, lockFileName)
+		}
+	}
+
+	var summary []string
+	for _, status := range []string{verifyOK, verifyModified, verifyTampered, verifyMissing, verifyUnverified} {
+		if counts[status] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	if len(summary) == 0 {
+		fmt.Println("Nothing to verify.")
+		return
+	}
+	fmt.Printf("\n%s\n", strings.Join(summary, ", "))
+	if counts[verifyModified] > 0 {
+		fmt.Println("💡 Run 'templui diff' to see local modifications.")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	installed := []byte("// header\npackage button\n")
+	upstreamData := []byte("package button\n")
+	path := filepath.ToSlash(filepath.Join(dir, "button.templ"))
+	writeTree(t, dir, map[string]string{"button.templ": string(installed)})
+	stripHeaderLine := func(data []byte) []byte {
+		_, rest, _ := bytes.Cut(data, []byte("\n"))
+		return rest
+	}
+	other := hashContent([]byte("package other\n"))
+
+	tests := []struct {
+		name         string
+		path         string
+		lockedSHA    string
+		publishedSHA string
+		want         string
+	}{
+		{name: "matches lock and registry", path: path, lockedSHA: hashContent(installed), publishedSHA: hashContent(upstreamData), want: verifyOK},
+		{name: "matches lock only", path: path, lockedSHA: hashContent(installed), want: verifyOK},
+		{name: "matches registry only", path: path, publishedSHA: hashContent(upstreamData), want: verifyOK},
+		{name: "differs from lock", path: path, lockedSHA: other, publishedSHA: hashContent(upstreamData), want: verifyModified},
+		{name: "matches lock but not registry", path: path, lockedSHA: hashContent(installed), publishedSHA: other, want: verifyTampered},
+		{name: "no hashes", path: path, want: verifyUnverified},
+		{name: "missing", path: path + ".missing", lockedSHA: hashContent(installed), want: verifyMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyFile(tt.path, tt.lockedSHA, tt.publishedSHA, stripHeaderLine); got != tt.want {
+				t.Errorf("verifyFile() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// whyResult explains why a component is installed.
+type whyResult struct {
+	Component string     `json:"component"`
+	Explicit  bool       `json:"explicit"`        // Whether it was requested directly
+	Paths     [][]string `json:"paths,omitempty"` // Dependency paths from explicitly installed components to it
+}
+
+// runWhy handles the 'why' command logic.
+func runWhy(args []string) int {
+	if len(args) != 1 {
+		failf("Error: Specify exactly one component after 'why'.\n")
+		fmt.Println("Usage: templui why <component>")
+		return exitUsage
+	}
+	name := args[0]
+
+	config, err := loadConfig()
+	if err != nil {
+		failf("Error loading config: %v\n", err)
+		return exitError
+	}
+	lock, err := loadLockfile()
+	if err != nil {
+		failf("Error loading lockfile: %v\n", err)
+		return exitError
+	}
+	installedNames, err := getInstalledComponentNames(config, lock)
+	if err != nil {
+		failf("Error detecting installed components: %v\n", err)
+		return exitError
+	}
+	if !slices.Contains(installedNames, name) {
+		failf("❌ Component '%s' is not installed.\n", name)
+		return exitError
+	}
+	deps := installedDependencies(installedNames, lock)
+
+	why := &whyResult{Component: name}
+	for _, root := range installedNames {
+		// Components missing from the lockfile are roots, as in orphanedDependencies.
+		if locked, ok := lock.Components[root]; ok && !locked.Explicit {
+			continue
+		}
+		if root == name {
+			why.Explicit = true
+			continue
+		}
+		why.Paths = append(why.Paths, dependencyPaths(root, name, deps, []string{root})...)
+	}
+	result.Why = why
+
+	if why.Explicit {
+		fmt.Printf("✅ %s was installed explicitly.\n", name)
+	}
+	if len(why.Paths) > 0 {
+		fmt.Printf("📦 %s is required by:\n", name)
+		for _, path := range why.Paths {
+			fmt.Printf("  %s\n", strings.Join(path, " → "))
+		}
+	}
+	if !why.Explicit && len(why.Paths) == 0 {
+		fmt.Printf("🧹 %s is not needed by any explicitly installed component.\n", name)
+		fmt.Println("   Run 'templui remove --prune' to remove it.")
+	}
+	return exitOK
+}
+
+// dependencyPaths returns every path of dependencies from the last component of path to target.
+// Components already on the path are not visited again, so cycles end the search.
+func dependencyPaths(from, target string, deps map[string]componentDeps, path []string) [][]string {
+	var paths [][]string
+	for _, dep := range deps[from].dependencies {
+		if slices.Contains(path, dep) {
+			continue
+		}
+		next := append(slices.Clone(path), dep)
+		if dep == target {
+			paths = append(paths, next)
+			continue
+		}
+		paths = append(paths, dependencyPaths(dep, target, deps, next)...)
+	}
+	return paths
+}
@@ -2,7 +2,6 @@ package registry
 
 import (
 	_ "embed"
-	"encoding/json"
 	"log"
 )
 
@@ -10,28 +9,59 @@ import (
 var registryJSON []byte
 
 // ComponentDef describes a single component within the registry.
+// The same types are used by the docs site and the templui CLI.
 type ComponentDef struct {
-	Name         string   `json:"name"`
-	Slug         string   `json:"slug"`
-	DisplayName  string   `json:"displayName"`
-	Description  string   `json:"description"`
-	Files        []string `json:"files"`
-	Dependencies []string `json:"dependencies"`
-	Categories   []string `json:"categories"`
-	Tags         []string `json:"tags"`
-	HasJS        bool     `json:"hasJS,omitempty"`
+	Name          string   `json:"name"`
+	Slug          string   `json:"slug"`
+	DisplayName   string   `json:"displayName"`
+	Description   string   `json:"description"`
+	Files         []string `json:"files"`                   // Paths relative to the repository root
+	Dependencies  []string `json:"dependencies"`            // Names of other required components
+	RequiredUtils []string `json:"requiredUtils,omitempty"` // Paths to required utils relative to the repository root
+	HasJS         bool     `json:"hasJS,omitempty"`         // Whether this component requires JavaScript
+	Categories    []string `json:"categories"`
+	Tags          []string `json:"tags"`
+	// Hashes holds the SHA-256 of each file, including the JavaScript file, keyed by repository path.
+	Hashes map[string]string `json:"hashes,omitempty"`
 }
 
 // UtilDef describes a single utility file within the registry.
 type UtilDef struct {
-	Path        string `json:"path"`
+	Path        string `json:"path"` // Path relative to the repository root
 	Description string `json:"description"`
+	SHA256      string `json:"sha256,omitempty"` // Hash of the file content in the repository
 }
 
+// SchemaVersion is the version of the registry.json format described by these types and registry.schema.json.
+// It is increased whenever older CLIs would misread the registry, e.g. when a field is renamed or changes meaning.
+const SchemaVersion = 1
+
+// SchemaURL is where the JSON Schema of registry.json is published.
+const SchemaURL = "https://raw.githubusercontent.com/templui/templui/main/internal/registry/registry.schema.json"
+
 // Registry defines the structure of the registry.json file.
 type Registry struct {
-	Components []ComponentDef `json:"components"`
-	Utils      []UtilDef      `json:"utils"`
+	Schema        string         `json:"$schema,omitempty"`       // URL of the JSON Schema, for editors
+	SchemaVersion int            `json:"schemaVersion"`           // Format version, 0 for registries published before it was introduced
+	MinCLIVersion string         `json:"minCliVersion,omitempty"` // Oldest CLI version that installs the components correctly
+	Components    []ComponentDef `json:"components"`
+	Utils         []UtilDef      `json:"utils"`
+}
+
+// FileHashes returns the published SHA-256 hashes of all files in the registry, keyed by repository path.
+func (r Registry) FileHashes() map[string]string {
+	hashes := make(map[string]string)
+	for _, comp := range r.Components {
+		for repoPath, sha := range comp.Hashes {
+			hashes[repoPath] = sha
+		}
+	}
+	for _, util := range r.Utils {
+		if util.SHA256 != "" {
+			hashes[util.Path] = util.SHA256
+		}
+	}
+	return hashes
 }
 
 var cachedRegistry *Registry
@@ -42,8 +72,7 @@ func Get() *Registry {
 		return cachedRegistry
 	}
 
-	var r Registry
-	err := json.Unmarshal(registryJSON, &r)
+	r, err := Parse(registryJSON)
 	if err != nil {
 		log.Printf("Error parsing registry.json: %v", err)
 		return &Registry{} // Return empty registry on error
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Filter returns the components in any of the given categories that also have any of the given tags.
+// An empty list of categories or tags matches every component.
+func (r Registry) Filter(categories, tags []string) []ComponentDef {
+	var matches []ComponentDef
+	for _, comp := range r.Components {
+		if matchesAny(comp.Categories, categories) && matchesAny(comp.Tags, tags) {
+			matches = append(matches, comp)
+		}
+	}
+	return matches
+}
+
+// matchesAny reports whether values contains any of wanted, ignoring case. It is true if nothing is wanted.
+func matchesAny(values, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(values, func(value string) bool {
+		return slices.ContainsFunc(wanted, func(w string) bool { return strings.EqualFold(value, w) })
+	})
+}
+
+// Search returns the components matching every word of a query, best matches first. Words are matched
+// against the name, display name, description and tags, in that order of relevance. Like the selectbox
+// search, a word also matches if its letters appear in order in the name or a tag (e.g. "dlg" matches "dialog").
+func Search(components []ComponentDef, query string) []ComponentDef {
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if word = normalizeSearchValue(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return components
+	}
+
+	type scored struct {
+		comp  ComponentDef
+		score int
+	}
+	var matches []scored
+	for _, comp := range components {
+		total := 0
+		for _, word := range words {
+			score := searchScore(comp, word)
+			if score == 0 {
+				total = 0
+				break
+			}
+			total += score
+		}
+		if total > 0 {
+			matches = append(matches, scored{comp, total})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	result := make([]ComponentDef, len(matches))
+	for i, match := range matches {
+		result[i] = match.comp
+	}
+	return result
+}
+
+// searchScore rates how well a single normalized word matches a component, 0 if it doesn't.
+func searchScore(comp ComponentDef, word string) int {
+	name := normalizeSearchValue(comp.Name)
+	displayName := normalizeSearchValue(comp.DisplayName)
+	description := strings.ToLower(comp.Description)
+
+	switch {
+	case name == word || displayName == word:
+		return 100
+	case strings.HasPrefix(name, word) || strings.HasPrefix(displayName, word):
+		return 80
+	case strings.Contains(name, word) || strings.Contains(displayName, word):
+		return 60
+	}
+	best := 0
+	for _, tag := range comp.Tags {
+		tag = normalizeSearchValue(tag)
+		switch {
+		case tag == word:
+			best = max(best, 50)
+		case strings.Contains(tag, word):
+			best = max(best, 40)
+		}
+	}
+	if best > 0 {
+		return best
+	}
+	if strings.Contains(description, word) {
+		return 30
+	}
+	if isSubsequence(word, name) || isSubsequence(word, displayName) {
+		return 20
+	}
+	for _, tag := range comp.Tags {
+		if isSubsequence(word, normalizeSearchValue(tag)) {
+			return 10
+		}
+	}
+	return 0
+}
+
+// normalizeSearchValue lowercases a value and drops spaces and dashes, so "Date Picker",
+// "date-picker" and "datepicker" compare equal.
+func normalizeSearchValue(value string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(value))
+}
+
+// isSubsequence reports whether the letters of needle appear in order in haystack.
+func isSubsequence(needle, haystack string) bool {
+	i := 0
+	for j := 0; j < len(haystack) && i < len(needle); j++ {
+		if haystack[j] == needle[i] {
+			i++
+		}
+	}
+	return i == len(needle)
+}
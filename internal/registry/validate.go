@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// componentNameRegex matches component names. Names become directory and Go package names.
+	componentNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// versionRegex matches CLI versions like v1.2.3 or v1.2.3-rc.1.
+	versionRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+	// sha256Regex matches hex-encoded SHA-256 hashes.
+	sha256Regex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// UnsupportedSchemaError is returned by Parse for registries of a newer schema version than SchemaVersion.
+type UnsupportedSchemaError struct {
+	Version int
+}
+
+func (e *UnsupportedSchemaError) Error() string {
+	return fmt.Sprintf("registry schema version %d is not supported (supported: up to %d)", e.Version, SchemaVersion)
+}
+
+// Parse decodes and validates a registry.json. Registries of a newer schema version are rejected with
+// an *UnsupportedSchemaError before anything else is checked, and unknown fields are rejected,
+// so a registry is never silently misread. Registries published before schemaVersion was introduced
+// may contain fields of older formats, which are ignored.
+func Parse(data []byte) (Registry, error) {
+	var header struct {
+		SchemaVersion json.RawMessage `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return Registry{}, describeJSONError(data, err, 0)
+	}
+	var version int
+	if json.Unmarshal(header.SchemaVersion, &version) == nil && version > SchemaVersion {
+		return Registry{}, &UnsupportedSchemaError{Version: version}
+	}
+
+	var r Registry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if version > 0 {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&r); err != nil {
+		return Registry{}, describeJSONError(data, err, decoder.InputOffset())
+	}
+	if err := r.Validate(); err != nil {
+		return Registry{}, err
+	}
+	return r, nil
+}
+
+// describeJSONError adds the line and column to a decoding error. offset is used for
+// errors that don't carry their own position, like unknown fields.
+func describeJSONError(data []byte, err error, offset int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+		if typeErr.Field != "" {
+			err = fmt.Errorf("%s must be %s, not %s", typeErr.Field, jsonTypeName(typeErr.Type.Kind().String()), typeErr.Value)
+		}
+	default:
+		// Unknown field errors carry no position, so point at the first key of that name.
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			key := regexp.MustCompile(regexp.QuoteMeta(field) + `\s*:`)
+			if loc := key.FindIndex(data); loc != nil {
+				offset = int64(loc[0])
+			}
+			err = fmt.Errorf("unknown field %s (from a newer schema or misspelled?)", field)
+		}
+	}
+	offset = min(max(offset, 0), int64(len(data)))
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := int(offset) - bytes.LastIndexByte(data[:offset], '\n')
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
+
+// jsonTypeName returns the JSON name of a Go kind.
+func jsonTypeName(kind string) string {
+	switch kind {
+	case "slice":
+		return "an array"
+	case "struct", "map":
+		return "an object"
+	case "bool":
+		return "a boolean"
+	case "int", "int64":
+		return "a number"
+	default:
+		return "a " + kind
+	}
+}
+
+// Validate checks the values of a registry that the JSON types can't express: required fields,
+// name and path formats, duplicates and hashes. All problems are returned joined.
+func (r Registry) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if r.SchemaVersion < 0 {
+		fail("schemaVersion must not be negative")
+	}
+	if r.MinCLIVersion != "" && !versionRegex.MatchString(r.MinCLIVersion) {
+		fail("minCliVersion %q is not a version like v1.2.3", r.MinCLIVersion)
+	}
+
+	names := make(map[string]bool)
+	for i, comp := range r.Components {
+		where := fmt.Sprintf("components[%d]", i)
+		if comp.Name != "" {
+			where += fmt.Sprintf(" (%s)", comp.Name)
+		}
+		switch {
+		case comp.Name == "":
+			fail("%s: name is required", where)
+		case !componentNameRegex.MatchString(comp.Name):
+			fail("%s: name must start with a lowercase letter and contain only lowercase letters, digits and '_'", where)
+		case names[comp.Name]:
+			fail("%s: duplicate component name", where)
+		}
+		names[comp.Name] = true
+
+		if len(comp.Files) == 0 {
+			fail("%s: files must not be empty", where)
+		}
+		files := make(map[string]bool)
+		for _, file := range comp.Files {
+			if err := validatePath(file); err != nil {
+				fail("%s: file %q %v", where, file, err)
+			}
+			files[file] = true
+		}
+		for _, dep := range comp.Dependencies {
+			if dep == "" || dep == comp.Name {
+				fail("%s: invalid dependency %q", where, dep)
+			}
+		}
+		for _, util := range comp.RequiredUtils {
+			if err := validatePath(util); err != nil {
+				fail("%s: required util %q %v", where, util, err)
+			}
+		}
+		for file, sha := range comp.Hashes {
+			if !files[file] && !(comp.HasJS && path.Base(file) == comp.Name+".min.js") {
+				fail("%s: hash for %q, which is not one of its files", where, file)
+			}
+			if !sha256Regex.MatchString(sha) {
+				fail("%s: hash of %q is not a hex-encoded SHA-256", where, file)
+			}
+		}
+	}
+
+	utils := make(map[string]bool)
+	for i, util := range r.Utils {
+		where := fmt.Sprintf("utils[%d]", i)
+		if err := validatePath(util.Path); err != nil {
+			fail("%s: path %q %v", where, util.Path, err)
+		} else if utils[util.Path] {
+			fail("%s: duplicate util path %q", where, util.Path)
+		}
+		utils[util.Path] = true
+		if util.SHA256 != "" && !sha256Regex.MatchString(util.SHA256) {
+			fail("%s: sha256 is not a hex-encoded SHA-256", where)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validatePath checks that a path is a clean path relative to the repository root.
+func validatePath(p string) error {
+	switch {
+	case p == "":
+		return errors.New("is empty")
+	case path.IsAbs(p) || strings.Contains(p, "\\"):
+		return errors.New("must be relative to the repository root, with '/' separators")
+	case path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../"):
+		return errors.New("must be a clean path inside the repository")
+	}
+	return nil
+}
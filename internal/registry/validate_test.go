@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// button is a valid component entry.
+const button = `{"name": "button", "slug": "button", "displayName": "Button", "description": "", "files": ["internal/components/button/button.templ"], "dependencies": [], "categories": [], "tags": []}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string // Substring of the error, "" if the registry is valid
+	}{
+		{
+			name: "valid",
+			data: `{"schemaVersion": 1, "components": [` + button + `], "utils": [{"path": "internal/utils/templui.go", "description": ""}]}`,
+		},
+		{
+			name:    "unknown field",
+			data:    "{\n  \"schemaVersion\": 1,\n  \"components\": [],\n  \"utils\": [],\n  \"plugins\": []\n}",
+			wantErr: `line 5, column 3: unknown field "plugins"`,
+		},
+		{
+			name:    "unknown component field",
+			data:    `{"schemaVersion": 1, "components": [{"name": "button", "file": []}], "utils": []}`,
+			wantErr: `unknown field "file"`,
+		},
+		{
+			name: "v0 registry ignores unknown fields",
+			data: `{"components": [` + button + `], "utils": [], "version": "0.1.0"}`,
+		},
+		{
+			name:    "v0 registry is still validated",
+			data:    `{"components": [{"name": "Button", "files": ["internal/components/button/button.templ"]}], "utils": []}`,
+			wantErr: "name must start with a lowercase letter",
+		},
+		{
+			name:    "syntax error",
+			data:    "{\n  \"schemaVersion\": 1,\n  \"components\": [,]\n}",
+			wantErr: "line 3, column",
+		},
+		{
+			name:    "wrong type",
+			data:    `{"schemaVersion": 1, "components": {}, "utils": []}`,
+			wantErr: "components must be an array",
+		},
+		{
+			name:    "duplicate component",
+			data:    `{"schemaVersion": 1, "components": [` + button + `, ` + button + `], "utils": []}`,
+			wantErr: "components[1] (button): duplicate component name",
+		},
+		{
+			name:    "component without name",
+			data:    `{"schemaVersion": 1, "components": [{"files": ["internal/components/button/button.templ"]}], "utils": []}`,
+			wantErr: "components[0]: name is required",
+		},
+		{
+			name:    "component without files",
+			data:    `{"schemaVersion": 1, "components": [{"name": "button", "files": []}], "utils": []}`,
+			wantErr: "components[0] (button): files must not be empty",
+		},
+		{
+			name:    "component depending on itself",
+			data:    `{"schemaVersion": 1, "components": [{"name": "button", "files": ["internal/components/button/button.templ"], "dependencies": ["button"]}], "utils": []}`,
+			wantErr: `invalid dependency "button"`,
+		},
+		{
+			name:    "path outside the repository",
+			data:    `{"schemaVersion": 1, "components": [{"name": "button", "files": ["../button.templ"]}], "utils": []}`,
+			wantErr: "must be a clean path inside the repository",
+		},
+		{
+			name:    "duplicate util",
+			data:    `{"schemaVersion": 1, "components": [], "utils": [{"path": "internal/utils/templui.go"}, {"path": "internal/utils/templui.go"}]}`,
+			wantErr: `utils[1]: duplicate util path "internal/utils/templui.go"`,
+		},
+		{
+			name:    "invalid hash",
+			data:    `{"schemaVersion": 1, "components": [{"name": "button", "files": ["internal/components/button/button.templ"], "hashes": {"internal/components/button/button.templ": "abc"}}], "utils": []}`,
+			wantErr: "is not a hex-encoded SHA-256",
+		},
+		{
+			name:    "invalid minCliVersion",
+			data:    `{"schemaVersion": 1, "minCliVersion": "1.0", "components": [], "utils": []}`,
+			wantErr: `minCliVersion "1.0" is not a version`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Parse() error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("Parse() succeeded, want an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("Parse() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseUnsupportedSchemaVersion(t *testing.T) {
+	// Unknown fields of a newer schema are reported as an unsupported schema, not as unknown fields.
+	_, err := Parse([]byte(`{"schemaVersion": 2, "components": "changed", "plugins": []}`))
+	var schemaErr *UnsupportedSchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Parse() error = %v, want an *UnsupportedSchemaError", err)
+	}
+	if schemaErr.Version != 2 {
+		t.Errorf("UnsupportedSchemaError.Version = %d, want 2", schemaErr.Version)
+	}
+}
+
+func TestParseEmbeddedRegistry(t *testing.T) {
+	r, err := Parse(registryJSON)
+	if err != nil {
+		t.Fatalf("registry.json is invalid: %v", err)
+	}
+	if r.SchemaVersion != SchemaVersion {
+		t.Errorf("registry.json has schemaVersion %d, want %d", r.SchemaVersion, SchemaVersion)
+	}
+}